@@ -0,0 +1,117 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Block is a Tree entry that holds a script: a sequence of statements separated by ';',
+// such as `a = :x: * 2; b = a + sqrt(:y:); a / b`.
+// The statements are evaluated in order and the value of the Block is that of its last
+// statement.
+type Block struct {
+	Statements []Tree
+}
+
+func NewBlock(statements ...Tree) Block {
+	return Block{
+		Statements: statements,
+	}
+}
+
+func (b Block) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := b.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the statements of the block in order and returns the value of the last one.
+// The evaluation stops at the first statement that is Undefined.
+// The names assigned in the block are local to the block.
+func (b Block) Eval(opts ...treeOption) Value {
+	cfg := &treeConfig{}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	sc := cfg.scope.push(Variables{})
+	blockOpts := append(cfg.options(), withScope(sc))
+
+	var val Value = NewUndefinedWithReasonf("syntax error: empty script")
+
+	for _, stmt := range b.Statements {
+		if len(stmt) == 0 {
+			// empty statements are ignored, as they are by the TreeBuilder
+			continue
+		}
+
+		if a, ok := stmt[0].(Assignment); ok && len(stmt) == 1 {
+			val = a.Value.Eval(blockOpts...)
+			if u, ok := val.(Undefined); ok {
+				return u
+			}
+
+			if cfg.mutableVariables && cfg.variables != nil && isUserVariableName(a.Name) {
+				cfg.variables[a.Name] = val
+				// a previous assignment in the script must not shadow the new value
+				delete(sc.vars, a.Name)
+				continue
+			}
+
+			sc.vars[a.Name] = val
+			continue
+		}
+
+		val = stmt.Eval(blockOpts...)
+		if u, ok := val.(Undefined); ok {
+			return u
+		}
+	}
+
+	return val
+}
+
+func (b Block) String() string {
+	stmts := lo.Map(b.Statements, func(item Tree, index int) string {
+		return strings.TrimRight(item.String(), "\n")
+	})
+	return fmt.Sprintf("{%s}", strings.Join(stmts, "; "))
+}
+
+// Assignment is a statement of a Block that assigns the value of an expression to a name,
+// such as `a = :x: * 2` or `:total: = a + 1`.
+// Bare names (e.g. `a`) are local to the Block.
+// User variables (e.g. `:total:`) are also local to the Block, unless the Tree is evaluated
+// WithMutableVariables.
+type Assignment struct {
+	Name  string
+	Value Tree
+}
+
+func NewAssignment(name string, value Tree) Assignment {
+	return Assignment{
+		Name:  name,
+		Value: value,
+	}
+}
+
+func (a Assignment) String() string {
+	return fmt.Sprintf("%s = %s", a.Name, strings.TrimRight(a.Value.String(), "\n"))
+}
+
+func isUserVariableName(name string) bool {
+	return len(name) > 2 && name[0] == ':' && name[len(name)-1] == ':'
+}
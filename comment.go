@@ -0,0 +1,81 @@
+package gal
+
+import "strings"
+
+// Comment is a comment found in an expression.
+// Comments are permitted everywhere blanks are:
+//   - `// line comment` extends to the end of the line
+//   - `/* block comment */` may span several lines
+//
+// Comments are ignored by the TreeBuilder. Use Comments to retrieve them, for instance
+// to keep them in the output of a formatter.
+type Comment struct {
+	// Text holds the comment, including its delimiters (i.e. "// ..." or "/* ... */").
+	Text string
+
+	// Offset is the position of the comment in bytes from the start of the expression.
+	// Line and Column are 1-based and Column counts characters (runes), not bytes.
+	Offset int
+	Line   int
+	Column int
+}
+
+// IsBlock returns true for a `/* block comment */`.
+func (c Comment) IsBlock() bool {
+	return strings.HasPrefix(c.Text, "/*")
+}
+
+// Comments returns the comments of expr, in the order they appear in the expression.
+// The comment delimiters found in string literals are ignored.
+func Comments(expr string) []Comment {
+	var comments []Comment
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return comments
+			}
+			i += l - 1
+		case c == ':':
+			if v, l, err := readVariable(expr[i:]); err == nil && len(v) > 2 {
+				i += l - 1
+			}
+		case c == '/' && commentLen(expr[i:]) > 0:
+			l := commentLen(expr[i:])
+			line, column := position(expr, i)
+			comments = append(comments, Comment{
+				Text:   expr[i : i+l],
+				Offset: i,
+				Line:   line,
+				Column: column,
+			})
+			i += l - 1
+		}
+	}
+
+	return comments
+}
+
+// commentLen returns the length of the comment at the start of expr, or 0 when there is none.
+// A line comment does not include the line feed that ends it.
+// An unterminated block comment is not a comment: extractPart reports it as an error.
+func commentLen(expr string) int {
+	switch {
+	case strings.HasPrefix(expr, "//"):
+		if i := strings.IndexByte(expr, '\n'); i >= 0 {
+			return i
+		}
+		return len(expr)
+
+	case strings.HasPrefix(expr, "/*"):
+		if i := strings.Index(expr[2:], "*/"); i >= 0 {
+			return i + 4
+		}
+		return 0
+
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,66 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conditional is a Tree entry that holds an `if(condition then else)` expression.
+// Unlike the arguments of a Function, the branches of a Conditional are not evaluated
+// ahead of time: only the branch selected by the condition is evaluated.
+type Conditional struct {
+	Condition Tree
+	Then      Tree
+	Else      Tree
+}
+
+func NewConditional(condition, then, els Tree) Conditional {
+	return Conditional{
+		Condition: condition,
+		Then:      then,
+		Else:      els,
+	}
+}
+
+func (c Conditional) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := c.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the condition and then, only the branch that it selects.
+func (c Conditional) Eval(opts ...treeOption) Value {
+	condVal := c.Condition.Eval(opts...)
+	if u, ok := condVal.(Undefined); ok {
+		return u
+	}
+
+	cond, ok := condVal.(Booler)
+	if !ok {
+		return NewUndefinedWithReasonf("if(): condition must be a Bool, got '%s'", condVal.String())
+	}
+
+	if cond.Bool().value {
+		return c.Then.Eval(opts...)
+	}
+
+	return c.Else.Eval(opts...)
+}
+
+func (c Conditional) String() string {
+	return fmt.Sprintf("if(%s, %s, %s)",
+		strings.TrimRight(c.Condition.String(), "\n"),
+		strings.TrimRight(c.Then.String(), "\n"),
+		strings.TrimRight(c.Else.String(), "\n"),
+	)
+}
@@ -3,6 +3,7 @@ package gal
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
@@ -20,6 +21,10 @@ type Function struct {
 	Receiver Value // experimental concept: not used yet
 	BodyFn   FunctionalValue
 	Args     []Tree
+
+	// Params declares the parameters of BodyFn, to which the arguments are bound.
+	// It is looked up at evaluation time, alongside BodyFn.
+	Params Parameters
 }
 
 func NewFunction(name string, bodyFn FunctionalValue, args ...Tree) Function {
@@ -34,10 +39,16 @@ func (f Function) Calculate(val entry, op Operator, cfg *treeConfig) entry {
 	if f.BodyFn == nil {
 		// attempt to get body of a user-defined function
 		// note: user-provided objects' methods are dealt with by ObjectMethod.Calculate
-		f.BodyFn = cfg.Function(f.Name)
+		f.BodyFn, f.Params = cfg.Function(f.Name)
+	} else if bodyFn, ok := cfg.functions.Get(f.Name); ok && userOverridableBuiltIns[strings.ToLower(f.Name)] {
+		// a user-defined function keeps its name over a more recent built-in function
+		f.BodyFn = bodyFn
+		f.Params, _ = cfg.functionParameters.Get(f.Name)
+	} else if f.Params == nil {
+		f.Params = BuiltInParameters(f.Name)
 	}
 
-	rhsVal := f.Eval(WithFunctions(cfg.functions), WithVariables(cfg.variables), WithObjects(cfg.objects))
+	rhsVal := f.Eval(cfg.options()...)
 	if u, ok := rhsVal.(Undefined); ok {
 		return u
 	}
@@ -63,7 +74,8 @@ func (f Function) String() string {
 func (f Function) Equal(other Function) bool {
 	return f.Name == other.Name &&
 		f.BodyFn.String() == other.BodyFn.String() &&
-		cmp.Equal(f.Args, other.Args)
+		cmp.Equal(f.Args, other.Args) &&
+		cmp.Equal(f.Params, other.Params)
 }
 
 func (f Function) Eval(opts ...treeOption) Value {
@@ -80,7 +92,16 @@ func (f Function) Eval(opts ...treeOption) Value {
 		f.BodyFn = bodyFn
 	}
 
+	var named []namedValue
+
 	for _, a := range f.Args {
+		if na, ok := namedArgument(a); ok {
+			named = append(named, namedValue{name: na.Name, value: na.Value.Eval(opts...)})
+			continue
+		}
+		if len(named) > 0 {
+			return NewUndefinedWithReasonf("%s(): positional argument #%d follows named argument '%s'", f.Name, len(args)+len(named)+1, named[len(named)-1].name)
+		}
 		args = append(args, a.Eval(opts...))
 	}
 
@@ -88,6 +109,11 @@ func (f Function) Eval(opts ...treeOption) Value {
 		return NewUndefinedWithReasonf("unknown function '%s'", f.Name)
 	}
 
+	args, u := f.Params.bind(f.Name, args, named)
+	if u != nil {
+		return u
+	}
+
 	return f.BodyFn(args...)
 }
 
@@ -100,9 +126,70 @@ var builtInFunctions = map[string]FunctionalValue{
 	"sqrt":      Sqrt,
 	"floor":     Floor,
 	"trunc":     Trunc,
+	"round":     Round,
 	"ln":        Ln,
 	"log":       Log,
+	"ediv":      EuclideanDiv,
+	"emod":      EuclideanMod,
 	"eval":      Eval,
+	"map":       MapValues,
+	"filter":    Filter,
+	"reduce":    Reduce,
+	"any":       Any,
+	"all":       All,
+	"sortby":    SortBy,
+	"match":     MatchRegexp,
+	"capture":   Capture,
+}
+
+// userOverridableBuiltIns lists the built-in functions that a user-defined function of the
+// same name takes precedence over (see WithFunctions). These built-in functions came after
+// the user-defined functions could be given their names.
+var userOverridableBuiltIns = map[string]bool{
+	"map":     true,
+	"filter":  true,
+	"reduce":  true,
+	"any":     true,
+	"all":     true,
+	"sortby":  true,
+	"ediv":    true,
+	"emod":    true,
+	"match":   true,
+	"capture": true,
+	"round":   true,
+}
+
+// builtInParameters declares the parameters of the built-in functions, so that they can be
+// called with named arguments (e.g. `round(:x: places=2)`).
+var builtInParameters = map[string]Parameters{
+	"factorial": {{Name: "value"}},
+	"cos":       {{Name: "value"}},
+	"sin":       {{Name: "value"}},
+	"tan":       {{Name: "value"}},
+	"sqrt":      {{Name: "value"}},
+	"floor":     {{Name: "value"}},
+	"trunc":     {{Name: "value"}, {Name: "precision", Default: NewNumberFromInt(0)}},
+	"round":     {{Name: "value"}, {Name: "places", Default: NewNumberFromInt(0)}, {Name: "mode", Default: NewString(RoundHalfUp)}},
+	"ln":        {{Name: "value"}, {Name: "precision"}},
+	"log":       {{Name: "value"}, {Name: "precision"}},
+	"ediv":      {{Name: "dividend"}, {Name: "divisor"}},
+	"emod":      {{Name: "dividend"}, {Name: "divisor"}},
+	"eval":      {{Name: "value"}},
+	"map":       {{Name: "values"}, {Name: "fn"}},
+	"filter":    {{Name: "values"}, {Name: "fn"}},
+	"reduce":    {{Name: "values"}, {Name: "fn"}, {Name: "initial"}},
+	"any":       {{Name: "values"}, {Name: "fn"}},
+	"all":       {{Name: "values"}, {Name: "fn"}},
+	"sortby":    {{Name: "values"}, {Name: "fn"}},
+	"match":     {{Name: "value"}, {Name: "pattern"}},
+	"capture":   {{Name: "value"}, {Name: "pattern"}},
+}
+
+// BuiltInParameters returns the parameters of a built-in function if known.
+// It returns `nil` when no built-in function exists by the specified name.
+func BuiltInParameters(name string) Parameters {
+	// note: for now function names are arbitrarily case-insensitive
+	return builtInParameters[strings.ToLower(name)]
 }
 
 // BuiltInFunction returns a built-in function body if known.
@@ -230,6 +317,70 @@ func Log(args ...Value) Value {
 	return NewUndefinedWithReasonf("log(): invalid argument type '%s'", args[0].String())
 }
 
+// EuclideanDiv returns the quotient of the Euclidean division of the first argument by the
+// second, i.e. such that the remainder (see EuclideanMod) is never negative.
+func EuclideanDiv(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("ediv() requires 2 arguments, got %d", len(args))
+	}
+
+	if v, ok := args[0].(Numberer); ok {
+		return v.Number().EuclideanDivide(args[1])
+	}
+
+	return NewUndefinedWithReasonf("ediv(): invalid argument type '%s'", args[0].String())
+}
+
+// EuclideanMod returns the remainder of the Euclidean division of the first argument by the
+// second. It is never negative: `emod(-7 3)` is 2.
+func EuclideanMod(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("emod() requires 2 arguments, got %d", len(args))
+	}
+
+	if v, ok := args[0].(Numberer); ok {
+		return v.Number().EuclideanMod(args[1])
+	}
+
+	return NewUndefinedWithReasonf("emod(): invalid argument type '%s'", args[0].String())
+}
+
+// MatchRegexp returns whether the first argument matches the regular expression in the second
+// argument. It is equivalent to the `=~` operator: `match(:code: "^[A-Z]{3}$")`.
+func MatchRegexp(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("match() requires 2 arguments, got %d", len(args))
+	}
+
+	return matchRegexp(args[0], args[1])
+}
+
+// Capture returns the capture groups of the leftmost match of the regular expression in the
+// second argument, in the first argument, as a MultiValue of Strings.
+// As with Go's regexp.FindStringSubmatch, the first value is the text of the whole match.
+// The MultiValue is empty when there is no match.
+func Capture(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("capture() requires 2 arguments, got %d", len(args))
+	}
+
+	re, u := regexpFromValue(args[1])
+	if u != nil {
+		return u
+	}
+
+	s, ok := args[0].(Stringer)
+	if !ok {
+		return NewUndefinedWithReasonf("capture(): value '%s' is not a String", args[0].String())
+	}
+
+	groups := lo.Map(re.FindStringSubmatch(s.AsString().value), func(g string, _ int) Value {
+		return NewString(g)
+	})
+
+	return NewMultiValue(groups...)
+}
+
 // Sqrt returns the square root.
 func Sqrt(args ...Value) Value {
 	if len(args) != 1 {
@@ -278,6 +429,31 @@ func Trunc(args ...Value) Value {
 	return NewUndefinedWithReasonf("trunc(): invalid argument #1 '%s'", argVal.String())
 }
 
+// Round rounds the first argument to the number of decimal places in the second argument,
+// according to the rounding mode in the third argument: `round(:x: places=2 mode="half_even")`.
+// The places default to 0 and the mode to "half_up" (see RoundHalfUp and its siblings).
+func Round(args ...Value) Value {
+	if len(args) != 3 {
+		return NewUndefinedWithReasonf("round() requires 3 arguments, got %d", len(args))
+	}
+
+	places, ok := args[1].(Numberer)
+	if !ok {
+		return NewUndefinedWithReasonf("round() requires places (argument #2) to be a number, got %s", args[1].String())
+	}
+
+	mode, ok := args[2].(Stringer)
+	if !ok {
+		return NewUndefinedWithReasonf("round() requires mode (argument #3) to be a string, got %s", args[2].String())
+	}
+
+	if v, ok := args[0].(Numberer); ok {
+		return v.Number().Round(int32(places.Number().value.IntPart()), mode.AsString().RawString()) //nolint:gosec // ignoring overflow conversion
+	}
+
+	return NewUndefinedWithReasonf("round(): invalid argument #1 '%s'", args[0].String())
+}
+
 func Eval(args ...Value) Value {
 	if len(args) != 1 {
 		return NewUndefinedWithReasonf("eval() requires 1 argument1, got %d: '%v'", len(args), args)
@@ -291,3 +467,199 @@ func Eval(args ...Value) Value {
 
 	return argVal
 }
+
+// collectionAndLambda returns the MultiValue and the Lambda that are the first two
+// arguments of the higher-order functions.
+// A Go slice or array held in an ObjectValue (e.g. an object property) is converted to a MultiValue.
+func collectionAndLambda(fname string, args []Value) (MultiValue, Lambda, Value) {
+	mv, ok := args[0].(MultiValue)
+	if objVal, isObj := args[0].(ObjectValue); isObj {
+		mv, ok = objectToMultiValue(objVal.Object)
+	}
+	if !ok {
+		return MultiValue{}, Lambda{}, NewUndefinedWithReasonf("%s(): argument #1 must be a MultiValue, got '%s'", fname, args[0].String())
+	}
+
+	fn, ok := args[1].(Lambda)
+	if !ok {
+		return MultiValue{}, Lambda{}, NewUndefinedWithReasonf("%s(): argument #2 must be a lambda, got '%s'", fname, args[1].String())
+	}
+
+	return mv, fn, nil
+}
+
+// MapValues returns a MultiValue that holds the result of the lambda applied to each value.
+// It is the built-in function map(values x -> ...).
+func MapValues(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("map() requires 2 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("map", args)
+	if u != nil {
+		return u
+	}
+
+	values := make([]Value, 0, mv.Size())
+	for _, v := range mv.values {
+		r := fn.Call(v)
+		if u, ok := r.(Undefined); ok {
+			return u
+		}
+		values = append(values, r)
+	}
+
+	return NewMultiValue(values...)
+}
+
+// Filter returns a MultiValue that holds the values for which the lambda returns True.
+func Filter(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("filter() requires 2 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("filter", args)
+	if u != nil {
+		return u
+	}
+
+	var values []Value
+	for _, v := range mv.values {
+		b, u := callPredicate("filter", fn, v)
+		if u != nil {
+			return u
+		}
+		if b {
+			values = append(values, v)
+		}
+	}
+
+	return NewMultiValue(values...)
+}
+
+// Reduce returns the result of the lambda applied to an accumulator, starting with the
+// initial value, and each value in turn.
+// It is the built-in function reduce(values (acc x) -> ... initial).
+func Reduce(args ...Value) Value {
+	if len(args) != 3 {
+		return NewUndefinedWithReasonf("reduce() requires 3 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("reduce", args)
+	if u != nil {
+		return u
+	}
+
+	acc := args[2]
+	for _, v := range mv.values {
+		acc = fn.Call(acc, v)
+		if u, ok := acc.(Undefined); ok {
+			return u
+		}
+	}
+
+	return acc
+}
+
+// Any returns True when the lambda returns True for at least one of the values.
+// It stops at the first value for which the lambda returns True.
+func Any(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("any() requires 2 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("any", args)
+	if u != nil {
+		return u
+	}
+
+	for _, v := range mv.values {
+		b, u := callPredicate("any", fn, v)
+		if u != nil {
+			return u
+		}
+		if b {
+			return True
+		}
+	}
+
+	return False
+}
+
+// All returns True when the lambda returns True for all of the values.
+// It stops at the first value for which the lambda returns False.
+func All(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("all() requires 2 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("all", args)
+	if u != nil {
+		return u
+	}
+
+	for _, v := range mv.values {
+		b, u := callPredicate("all", fn, v)
+		if u != nil {
+			return u
+		}
+		if !b {
+			return False
+		}
+	}
+
+	return True
+}
+
+// SortBy returns a MultiValue that holds the values sorted in ascending order of the key
+// the lambda returns for each of them.
+// The sort is stable: values with equal keys keep their original order.
+func SortBy(args ...Value) Value {
+	if len(args) != 2 {
+		return NewUndefinedWithReasonf("sortBy() requires 2 arguments, got %d", len(args))
+	}
+
+	mv, fn, u := collectionAndLambda("sortBy", args)
+	if u != nil {
+		return u
+	}
+
+	keys := make([]Value, 0, mv.Size())
+	for _, v := range mv.values {
+		k := fn.Call(v)
+		if u, ok := k.(Undefined); ok {
+			return u
+		}
+		keys = append(keys, k)
+	}
+
+	idx := make([]int, mv.Size())
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		return keys[idx[i]].LessThan(keys[idx[j]]) == True
+	})
+
+	values := make([]Value, 0, mv.Size())
+	for _, i := range idx {
+		values = append(values, mv.values[i])
+	}
+
+	return NewMultiValue(values...)
+}
+
+func callPredicate(fname string, fn Lambda, v Value) (bool, Value) {
+	r := fn.Call(v)
+	if u, ok := r.(Undefined); ok {
+		return false, u
+	}
+
+	b, ok := r.(Booler)
+	if !ok {
+		return false, NewUndefinedWithReasonf("%s(): lambda must return a Bool, got '%s'", fname, r.String())
+	}
+
+	return b.Bool().value, nil
+}
@@ -78,6 +78,23 @@ func TestLog(t *testing.T) {
 	assert.Equal(t, "7", val.String())
 }
 
+func TestRound(t *testing.T) {
+	val := gal.Round(gal.NewNumberFromFloat(2.345), gal.NewNumberFromInt(2), gal.NewString(gal.RoundHalfUp))
+	assert.Equal(t, "2.35", val.String())
+
+	val = gal.Round(gal.NewNumberFromFloat(2.345), gal.NewNumberFromInt(2), gal.NewString(gal.RoundHalfEven))
+	assert.Equal(t, "2.34", val.String())
+
+	val = gal.Round(gal.NewNumberFromFloat(-2.341), gal.NewNumberFromInt(2), gal.NewString(gal.RoundFloor))
+	assert.Equal(t, "-2.35", val.String())
+
+	val = gal.Round(gal.NewNumberFromFloat(2.345), gal.NewNumberFromInt(2), gal.NewString("half_odd"))
+	assert.Equal(t, "undefined: unknown rounding mode 'half_odd'", val.String())
+
+	val = gal.Round(gal.NewNumberFromFloat(2.345))
+	assert.Equal(t, "undefined: round() requires 3 arguments, got 1", val.String())
+}
+
 func TestFunctionEval(t *testing.T) {
 	expr := `eval("7+22")*2`
 	tree, err := gal.NewTreeBuilder().FromExpr(expr)
@@ -85,3 +102,24 @@ func TestFunctionEval(t *testing.T) {
 
 	assert.Equal(t, "58", tree.Eval().String())
 }
+
+func TestHigherOrderFunctions(t *testing.T) {
+	values := gal.NewMultiValue(gal.NewNumberFromInt(3), gal.NewNumberFromInt(1), gal.NewNumberFromInt(2))
+	double := gal.NewLambda([]string{"x"}, gal.Tree{gal.NewVariable("x"), gal.Multiply, gal.NewNumberFromInt(2)})
+	isBig := gal.NewLambda([]string{"x"}, gal.Tree{gal.NewVariable("x"), gal.GreaterThan, gal.NewNumberFromInt(1)})
+	sum := gal.NewLambda([]string{"acc", "x"}, gal.Tree{gal.NewVariable("acc"), gal.Plus, gal.NewVariable("x")})
+
+	assert.Equal(t, "6,2,4", gal.MapValues(values, double).String())
+	assert.Equal(t, "3,2", gal.Filter(values, isBig).String())
+	assert.Equal(t, "6", gal.Reduce(values, sum, gal.NewNumberFromInt(0)).String())
+	assert.Equal(t, gal.True, gal.Any(values, isBig))
+	assert.Equal(t, gal.False, gal.All(values, isBig))
+	assert.Equal(t, gal.True, gal.All(gal.NewMultiValue(), isBig))
+	assert.Equal(t, "1,2,3", gal.SortBy(values, gal.NewLambda([]string{"x"}, gal.Tree{gal.NewVariable("x")})).String())
+
+	assert.Equal(t, "undefined: map() requires 2 arguments, got 1", gal.MapValues(values).String())
+	assert.Equal(t, "undefined: filter(): argument #1 must be a MultiValue, got '1'", gal.Filter(gal.NewNumberFromInt(1), isBig).String())
+	assert.Equal(t, `undefined: any(): argument #2 must be a lambda, got '"x"'`, gal.Any(values, gal.NewString("x")).String())
+	assert.Equal(t, `undefined: all(): lambda must return a Bool, got '"x"'`, gal.All(values, gal.NewLambda([]string{"x"}, gal.Tree{gal.NewString("x")})).String())
+	assert.Equal(t, "undefined: lambda '(acc x) -> Variable acc\nOperator +\nVariable x' requires 2 argument(s), got 1", gal.MapValues(values, sum).String())
+}
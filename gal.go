@@ -9,21 +9,23 @@ const (
 	operatorType
 	stringType
 	boolType
+	nullType
 	variableType
 	functionType
 	objectPropertyType           // "cousin" of a variableType, but for a property of a user-defined object
 	objectMethodType             // "cousin" of a functionType, but for a method of a user-defined object
 	objectAccessorByPropertyType // represents an object accessor of a "left hand side" expression by property
 	objectAccessorByMethodType   // represents an object accessor of a "left hand side" expression by method
+	listType                     // represents a list literal i.e. "[v1 v2 ...]"
+	mapType                      // represents a map literal i.e. `{"k1": v1, "k2": v2, ...}`
+	indexType                    // represents an index accessor of a "left hand side" expression i.e. "[i]" or "[i:j]"
 )
 
 // Example: Parse("blah").Eval(WithVariables(...), WithFunctions(...), WithObjects(...))
 // This allows to parse an expression and then use the resulting Tree for multiple
 // evaluations with different variables provided.
 func Parse(expr string) Tree {
-	treeBuilder := NewTreeBuilder()
-
-	tree, err := treeBuilder.FromExpr(expr)
+	tree, err := ParseE(expr)
 	if err != nil {
 		return Tree{
 			NewUndefinedWithReasonf("%s", err.Error()),
@@ -32,3 +34,11 @@ func Parse(expr string) Tree {
 
 	return tree
 }
+
+// ParseE is the same as Parse but it returns syntax errors rather than a Tree
+// that holds an Undefined value.
+// Syntax errors are of type *ParseError: they carry the position in expr where
+// the problem was found.
+func ParseE(expr string) (Tree, error) {
+	return NewTreeBuilder().FromExpr(expr)
+}
@@ -1,6 +1,7 @@
 package gal_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -38,6 +39,14 @@ func TestEval(t *testing.T) {
 	expr = `2+Factorial(4)-5`
 	val = gal.Parse(expr).Eval()
 	assert.Equal(t, gal.NewNumberFromInt(21).String(), val.String())
+
+	expr = `0xFF + 0b1010 * 0o10 - 1_000 + 2.5e2 - 1E-2`
+	val = gal.Parse(expr).Eval()
+	assert.Equal(t, gal.NewNumberFromFloat(0xFF+0b1010*0o10-1_000+2.5e2-1e-2).String(), val.String())
+
+	expr = `-"0x10"+"1e1"`
+	val = gal.Parse(expr).Eval()
+	assert.Equal(t, gal.NewNumberFromInt(-6).String(), val.String())
 }
 
 func TestTreeBuilder_FromExpr_Variables(t *testing.T) {
@@ -69,6 +78,400 @@ func TestTreeBuilder_FromExpr_UnknownVariable(t *testing.T) {
 	}
 }
 
+func TestEval_Bitwise(t *testing.T) {
+	vars := gal.Variables{
+		":flags:": gal.NewNumberFromInt(0b1011_0110),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"and":                    {expr: `:flags: & 0x0F`, want: "6"},
+		"or":                     {expr: `:flags: | 0b1`, want: "183"},
+		"xor":                    {expr: `:flags: ^ 0xFF`, want: "73"},
+		"not":                    {expr: `~:flags:`, want: "-183"},
+		"not in an operation":    {expr: `:flags: & ~0b110`, want: "176"},
+		"precedence":             {expr: `1 | 6 ^ 3 & 5`, want: "7"},
+		"shift before and":       {expr: `1 << 4 & 0xF0`, want: "16"},
+		"and before comparison":  {expr: `:flags: & 0x0F == 0x06`, want: "True"},
+		"logical and is not &":   {expr: `True && 1 & 1 == 1`, want: "True"},
+		"beyond 64 bits":         {expr: `2 ** 70 | 1`, want: "1180591620717411303425"},
+		"non-integer operand":    {expr: `1.5 & 1`, want: "undefined: invalid non-integer bitwise and: 1.5, 1"},
+		"non-number operand":     {expr: `~"abc"`, want: `undefined: syntax error: operator '~' requires a Number, got '"abc"'`},
+		"bitwise not of a group": {expr: `~(2 + 3)`, want: "-6"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_IntegerDivision(t *testing.T) {
+	vars := gal.Variables{
+		":minutes:": gal.NewNumberFromInt(52),
+		":offset:":  gal.NewNumberFromInt(-10),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"floor division":            {expr: `:minutes: ~/ 15`, want: "3"},
+		"floor division negative":   {expr: `-7 ~/ 2`, want: "-4"},
+		"floor modulo":              {expr: `:offset: %% 7`, want: "4"},
+		"truncated modulo":          {expr: `:offset: % 7`, want: "-3"},
+		"floor modulo sign":         {expr: `10 %% -7`, want: "-4"},
+		"multiplicative precedence": {expr: `1 + :minutes: ~/ 15 * 15`, want: "46"},
+		"euclidean division":        {expr: `ediv(-7 -2)`, want: "4"},
+		"euclidean modulo":          {expr: `emod(:offset: -7)`, want: "4"},
+		"division by zero":          {expr: `1 ~/ 0`, want: "undefined: division by zero"},
+		"missing argument":          {expr: `emod(1)`, want: "undefined: emod() requires 2 arguments, got 1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_InterpolatedString(t *testing.T) {
+	vars := gal.Variables{
+		":id:":    gal.NewNumberFromInt(42),
+		":limit:": gal.NewNumberFromFloat(1234.5678),
+		":items:": gal.NewMultiValue(gal.NewString("a"), gal.NewString("b")),
+	}
+	funcs := gal.Functions{
+		"upper": func(args ...gal.Value) gal.Value {
+			return gal.NewString(strings.ToUpper(args[0].AsString().RawString()))
+		},
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"variables and functions": {expr: `"Order ${:id:} exceeds ${trunc(:limit: 2)}"`, want: `"Order 42 exceeds 1234.56"`},
+		"user function":           {expr: `'${upper("ok")}!'`, want: `"OK!"`},
+		"bool":                    {expr: `"${:id: > 40}"`, want: `"True"`},
+		"nested strings":          {expr: `"${"a" + "${:id:}"}"`, want: `"a42"`},
+		"operand":                 {expr: `"#" + "${:id: * 2}" + "#"`, want: `"#84#"`},
+		"lambda parameter":        {expr: `map(:items: x -> "<${x}>")`, want: `"<a>","<b>"`},
+		"let binding":             {expr: `let n = 3 in "n=${n}"`, want: `"n=3"`},
+		"escaped":                 {expr: `"\${:id:}"`, want: `"\${:id:}"`},
+		"undefined":               {expr: `"${:nope:}"`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars), gal.WithFunctions(funcs))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// a String prints back to a literal of the same value, even when it holds "${".
+	s := gal.NewString("${:id:}")
+	assert.Equal(t, s, gal.Parse(s.String()).Eval(gal.WithVariables(vars)))
+}
+
+func TestEval_Membership(t *testing.T) {
+	vars := gal.Variables{
+		":country:": gal.NewString("FR"),
+		":allowed:": gal.NewMultiValue(gal.NewString("GB"), gal.NewString("FR")),
+		":codes:":   gal.NewMultiValue(gal.NewNumberFromInt(1), gal.NewNumberFromInt(2)),
+		":prices:":  gal.NewMap(gal.MapEntry{Key: "FR", Value: gal.NewNumberFromInt(10)}),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"in list":                  {expr: `:country: in :allowed:`, want: "True"},
+		"not in list":              {expr: `:country: not in :allowed:`, want: "False"},
+		"number in list":           {expr: `1 + 1 in :codes:`, want: "True"},
+		"list literal":             {expr: `"DE" in ["GB" "FR"]`, want: "False"},
+		"substring":                {expr: `"ell" in "hello"`, want: "True"},
+		"not a substring":          {expr: `"xyz" not in "hello"`, want: "True"},
+		"map key":                  {expr: `:country: in :prices:`, want: "True"},
+		"map literal key":          {expr: `"b" in {"a": 1}`, want: "False"},
+		"with logical operators":   {expr: `:country: in :allowed: && 3 not in :codes:`, want: "True"},
+		"in a lambda":              {expr: `filter(["GB" "DE" "FR"] c -> c in :allowed:)`, want: `"GB","FR"`},
+		"let value in parentheses": {expr: `let ok = (:country: in :allowed:) in ok`, want: "True"},
+		"not a container":          {expr: `1 in 2`, want: "undefined: operator 'in' requires a MultiValue, a String or a Map, got '2'"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_Null(t *testing.T) {
+	vars := gal.Variables{
+		":discount:": gal.NewNumberFromInt(10),
+		":nothing:":  gal.NewNull(),
+		":list:":     gal.ToValue([]any{1, nil}),
+		":customer:": gal.ObjectValue{Object: &Customer{Name: "Bob"}},
+		":items:":    gal.ObjectValue{Object: map[string]*Item{"pen": nil}},
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"literal":                 {expr: `Null`, want: "Null"},
+		"equal":                   {expr: `:nothing: == Null`, want: "True"},
+		"not equal":               {expr: `"Null" != Null`, want: "True"},
+		"in list":                 {expr: `Null in :list:`, want: "True"},
+		"arithmetic":              {expr: `:nothing: + 1`, want: "undefined: operator '+' cannot be applied to Null: 'Null' + '1'"},
+		"coalesce set":            {expr: `:discount: ?? 0`, want: "10"},
+		"coalesce Null":           {expr: `:nothing: ?? 0`, want: "0"},
+		"coalesce unknown":        {expr: `:unknown: ?? 0`, want: "0"},
+		"coalesce chain":          {expr: `:unknown: ?? :nothing: ?? "none"`, want: `"none"`},
+		"coalesce last":           {expr: `:unknown: ?? :nothing:`, want: "Null"},
+		"coalesce precedence":     {expr: `:unknown: ?? 1 + 1 > 1`, want: "True"},
+		"coalesce in parentheses": {expr: `(:unknown: ?? 1) * 3`, want: "3"},
+		"coalesce other errors":   {expr: `1 / 0 ?? 2`, want: "undefined: division by zero"},
+		"coalesce missing rhs":    {expr: `:unknown: ??`, want: "undefined: syntax error: missing right hand side value for operator '??'"},
+		"default unknown":         {expr: `:unknown|5: * 2`, want: "10"},
+		"default Null":            {expr: `:nothing|"n/a":`, want: `"n/a"`},
+		"default set":             {expr: `:discount|0:`, want: "10"},
+		"default interpolated":    {expr: `"${:unknown|-1:}"`, want: `"-1"`},
+		"unknown":                 {expr: `:unknown: + 1`, want: "undefined: error: unknown user-defined variable ':unknown:'"},
+		"object":                  {expr: `:customer:`, want: "ObjectValue(*gal_test.Customer)"},
+		"object equal Null":       {expr: `:customer: == Null`, want: "False"},
+		"object coalesce":         {expr: `:customer: ?? 0`, want: "ObjectValue(*gal_test.Customer)"},
+		"nil map element":         {expr: `:items:["pen"]?.Name ?? "none"`, want: `"none"`},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	got := gal.Parse(`:unknown: == Null`).Eval(gal.WithVariables(vars), gal.WithMissingVariablesAsNull())
+	assert.Equal(t, gal.True, got)
+
+	assert.False(t, gal.NewNull().IsUndefined())
+
+	// only the value of an unknown variable is replaced, whatever the reason of other Undefined values
+	got = gal.Parse(`f() ?? 1`).Eval(
+		gal.WithFunctions(gal.Functions{"f": func(...gal.Value) gal.Value {
+			return gal.NewUndefinedWithReasonf("error: unknown user-defined variable ':x:'")
+		}}),
+	)
+	assert.Equal(t, "undefined: error: unknown user-defined variable ':x:'", got.String())
+
+	got = gal.Parse(`f(:unknown:) + 1`).Eval(
+		gal.WithMissingVariablesAsNull(),
+		gal.WithFunctions(gal.Functions{"f": func(args ...gal.Value) gal.Value { return gal.NewNumberFromInt(int64(len(args))) }}),
+	)
+	assert.Equal(t, gal.NewNumberFromInt(2), got)
+
+	// the nested expressions are evaluated in the same mode
+	got = gal.Parse(`let y = :unknown: in map([1 2] x -> [y :other:] == [Null Null])`).Eval(gal.WithMissingVariablesAsNull())
+	assert.Equal(t, "True,True", got.String())
+}
+
+func TestEval_CommaArguments(t *testing.T) {
+	vars := gal.Variables{
+		":a:":     gal.NewNumberFromInt(5),
+		":b:":     gal.NewNumberFromInt(2),
+		":items:": gal.ToValue([]any{1, 2, 3}),
+	}
+	funcs := gal.Functions{
+		"count": func(args ...gal.Value) gal.Value {
+			return gal.NewNumberFromInt(int64(len(args)))
+		},
+	}
+
+	testCases := map[string]struct {
+		sep  gal.ArgumentSeparator
+		expr string
+		want string
+	}{
+		"space: sign starts an argument":     {sep: gal.SpaceSeparator, expr: `count(1 -2)`, want: "2"},
+		"space: blank operator":              {sep: gal.SpaceSeparator, expr: `count(1 - 2)`, want: "1"},
+		"comma: unary minus":                 {sep: gal.CommaSeparator, expr: `count(1, -2) + ediv(-7, 2)`, want: "-2"},
+		"comma: sign is an operator":         {sep: gal.CommaSeparator, expr: `count(1 -2) + trunc(:a: -:b:, 0)`, want: "4"},
+		"comma: nested calls":                {sep: gal.CommaSeparator, expr: `count(count(1, 2), ediv(7, 2))`, want: "2"},
+		"comma: list":                        {sep: gal.CommaSeparator, expr: `[1, 2 + 3, -4]`, want: "1,5,-4"},
+		"comma: lambda":                      {sep: gal.CommaSeparator, expr: `reduce(:items:, (acc, x) -> acc + x, 10)`, want: "16"},
+		"comma: if":                          {sep: gal.CommaSeparator, expr: `if(:a: > 1, "big", "small")`, want: `"big"`},
+		"comma: comma in string and comment": {sep: gal.CommaSeparator, expr: `count(",", 1 /* a, b */)`, want: "2"},
+		"comma: no arguments":                {sep: gal.CommaSeparator, expr: `count()`, want: "0"},
+		"comma: blank separator":             {sep: gal.CommaSeparator, expr: `count(1 2)`, want: "undefined: syntax error: missing operator or ',' in argument '1 2' (line 1, column 7)"},
+		"comma: trailing comma":              {sep: gal.CommaSeparator, expr: `count(1,)`, want: "undefined: syntax error: missing argument in '1,' (line 1, column 9)"},
+		"both: commas":                       {sep: gal.CommaOrSpaceSeparator, expr: `count(1 -2, 3)`, want: "2"},
+		"both: blanks":                       {sep: gal.CommaOrSpaceSeparator, expr: `count(1 -2 3)`, want: "3"},
+		"both: list":                         {sep: gal.CommaOrSpaceSeparator, expr: `[1 2][1] + [3, -4][1]`, want: "-2"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tree, err := gal.NewTreeBuilder(gal.WithArgumentSeparator(tc.sep)).FromExpr(tc.expr)
+			if err != nil {
+				assert.Equal(t, tc.want, "undefined: "+err.Error())
+				return
+			}
+			got := tree.Eval(gal.WithVariables(vars), gal.WithFunctions(funcs))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_UserFunctionsOverBuiltIns(t *testing.T) {
+	funcs := gal.Functions{
+		"map": func(args ...gal.Value) gal.Value {
+			return gal.NewString("user map")
+		},
+		"ediv": func(args ...gal.Value) gal.Value {
+			return gal.NewString("user ediv")
+		},
+		"match": func(args ...gal.Value) gal.Value {
+			return gal.NewString("user match")
+		},
+		"round": func(args ...gal.Value) gal.Value {
+			return gal.NewNumberFromInt(int64(len(args)))
+		},
+		"cos": func(args ...gal.Value) gal.Value {
+			return gal.NewString("user cos")
+		},
+	}
+
+	got := gal.Parse(`map(1)`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, `"user map"`, got.String())
+
+	got = gal.Parse(`ediv(7 2)`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, `"user ediv"`, got.String())
+
+	got = gal.Parse(`emod(7 2)`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, "1", got.String())
+
+	got = gal.Parse(`match("a" "a")`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, `"user match"`, got.String())
+
+	got = gal.Parse(`round(1.5)`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, "1", got.String())
+
+	got = gal.Parse(`round(1.5)`).Eval()
+	assert.Equal(t, "2", got.String())
+
+	// the built-in functions that pre-date this rule keep precedence
+	got = gal.Parse(`cos(0)`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, "1", got.String())
+}
+
+func TestEval_NamedArguments(t *testing.T) {
+	vars := gal.Variables{
+		":x:":      gal.NewNumberFromFloat(2.345),
+		":items:":  gal.ToValue([]any{3, 1, 2}),
+		":orders:": gal.NewMultiValue(gal.ObjectValue{Object: &Order{ID: "1", Customer: &Customer{Name: "Alice"}}}),
+	}
+	funcs := gal.Functions{
+		"fee": func(args ...gal.Value) gal.Value {
+			return gal.ToNumber(args[0]).Multiply(args[1])
+		},
+		"count": func(args ...gal.Value) gal.Value {
+			return gal.NewNumberFromInt(int64(len(args)))
+		},
+	}
+	params := gal.FunctionParameters{
+		"fee": {{Name: "amount"}, {Name: "rate", Default: gal.NewNumberFromFloat(0.2)}},
+	}
+	objects := gal.Objects{
+		"customer": &Customer{Name: "Bob"},
+		"order":    &Order{ID: "2", Customer: &Customer{Name: "Carol"}},
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"built-in defaults":         {expr: `round(:x:)`, want: "2"},
+		"built-in named":            {expr: `round(:x: places=2 mode="half_even")`, want: "2.34"},
+		"built-in named any order":  {expr: `round(mode="down" value=:x: places=1)`, want: "2.3"},
+		"named expression":          {expr: `round(:x: places=1 + 1) * 2`, want: "4.7"},
+		"named lambda":              {expr: `reduce(:items: fn=(acc x) -> acc + x initial=10)`, want: "16"},
+		"user function default":     {expr: `fee(100)`, want: "20"},
+		"user function named":       {expr: `fee(rate=0.1 amount=100)`, want: "10"},
+		"local lambda":              {expr: `let f = (a b) -> a - b in f(b=1 a=3)`, want: "2"},
+		"object method":             {expr: `customer.Discount(100)`, want: "10"},
+		"object method path":        {expr: `order.Customer.Discount(100 rate=0.5)`, want: "50"},
+		"object method accessor":    {expr: `map(:orders: o -> o.Customer.Discount(rate=0.3 amount=10))`, want: "3"},
+		"unknown name":              {expr: `round(:x: place=2)`, want: "undefined: round(): unknown argument 'place'"},
+		"duplicate name":            {expr: `round(:x: places=2 places=3)`, want: "undefined: round(): duplicate argument 'places'"},
+		"name of positional":        {expr: `fee(100 amount=5)`, want: "undefined: fee(): duplicate argument 'amount'"},
+		"missing required":          {expr: `fee(rate=0.1)`, want: "undefined: fee(): missing argument 'amount'"},
+		"positional after named":    {expr: `round(places=2 :x:)`, want: "undefined: round(): positional argument #2 follows named argument 'places'"},
+		"undeclared parameters":     {expr: `count(a=1)`, want: "undefined: count(): named argument 'a': the function does not declare its parameters"},
+		"positional without params": {expr: `count(1 2 3)`, want: "3"},
+		"arity left to the body":    {expr: `emod(1)`, want: "undefined: emod() requires 2 arguments, got 1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(
+				gal.WithVariables(vars),
+				gal.WithFunctions(funcs),
+				gal.WithFunctionParameters(params),
+				gal.WithObjects(objects),
+			)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// named arguments are also comma-separated
+	tree, err := gal.NewTreeBuilder(gal.WithArgumentSeparator(gal.CommaSeparator)).FromExpr(`round(:x:, places=2, mode="up")`)
+	require.NoError(t, err)
+	assert.Equal(t, "2.35", tree.Eval(gal.WithVariables(vars)).String())
+}
+
+func TestEval_Regexp(t *testing.T) {
+	vars := gal.Variables{
+		":code:":    gal.NewString("ABC"),
+		":pattern:": gal.NewString(`^\d+$`),
+		":bad:":     gal.NewString(`[a-`),
+		":date:":    gal.NewString("2024-03-15"),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"literal pattern":       {expr: `:code: =~ "^[A-Z]{3}$"`, want: "True"},
+		"raw literal pattern":   {expr: "\"123\" =~ `^\\d+$`", want: "True"},
+		"not match":             {expr: `:code: !~ "^[A-Z]{3}$"`, want: "False"},
+		"dynamic pattern":       {expr: `"42" =~ :pattern:`, want: "True"},
+		"dynamic not match":     {expr: `:code: !~ :pattern:`, want: "True"},
+		"interpolated pattern":  {expr: `:code: =~ "^${:code:}$"`, want: "True"},
+		"number value":          {expr: `123 =~ :pattern:`, want: "True"},
+		"invalid literal":       {expr: `:code: =~ "[a-"`, want: "undefined: invalid regular expression \"[a-\": error parsing regexp: missing closing ]: `[a-`"},
+		"invalid dynamic":       {expr: `:code: =~ :bad:`, want: "undefined: invalid regular expression \"[a-\": error parsing regexp: missing closing ]: `[a-`"},
+		"non-string pattern":    {expr: `:code: =~ 1`, want: "undefined: regular expression must be a String, got '1'"},
+		"match function":        {expr: `match(:code: "B")`, want: "True"},
+		"capture":               {expr: `capture(:date: "(\\d{4})-(\\d{2})")`, want: `"2024-03","2024","03"`},
+		"capture group":         {expr: `capture(:date: "(\\d{4})-(\\d{2})")[2]`, want: `"03"`},
+		"capture without match": {expr: `capture(:code: "\\d")`, want: ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
 func TestEval_Boolean(t *testing.T) {
 	expr := `2 > 1`
 	val := gal.Parse(expr).Eval()
@@ -118,10 +521,6 @@ func TestEval_Boolean(t *testing.T) {
 	val = gal.Parse(expr).Eval()
 	assert.Equal(t, gal.False.String(), val.String())
 
-	// TODO: idea: it should be easy to add support for "If" blocks.
-	// ...   It could be in the form of am extension to the Bool type.
-	// ...   Or, we could introduce a new built-in function "If" that returns a type that has methods Then() and Else()
-	// ...   Or, it could be a built-in Object "If" that has methods Then() and Else()
 	expr = `( 123 == 123 && 12 <= 45 ) Or ( "a" != "b" )`
 	val = gal.Parse(expr).Eval()
 	assert.Equal(t, gal.True.String(), val.String())
@@ -398,31 +797,11 @@ func TestObjects_Chained_Properties(t *testing.T) {
 	parsedExpr := gal.Parse(expr)
 
 	expectedTree := gal.Tree{
-		gal.NewObjectProperty("aCar", "Stereo"),
-		gal.DotVariable{
-			gal.NewVariable(
-				"Brand",
-			),
-		},
-		gal.DotVariable{
-			gal.NewVariable(
-				"Name",
-			),
-		},
+		gal.NewObjectProperty("aCar", "Stereo.Brand.Name"),
 		gal.Plus,
 		gal.NewString("::"),
 		gal.Plus,
-		gal.NewObjectProperty("aCar", "Stereo"),
-		gal.DotVariable{
-			gal.NewVariable(
-				"Brand",
-			),
-		},
-		gal.DotVariable{
-			gal.NewVariable(
-				"Country",
-			),
-		},
+		gal.NewObjectProperty("aCar", "Stereo.Brand.Country"),
 	}
 
 	require.Equal(t, expectedTree, parsedExpr)
@@ -447,6 +826,118 @@ func TestObjects_Chained_Properties(t *testing.T) {
 	assert.Equal(t, "Mitsubishi::Japan", got.AsString().RawString())
 }
 
+func TestObjects_OptionalChaining(t *testing.T) {
+	expr := `order.Customer?.Address.City`
+	parsedExpr := gal.Parse(expr)
+
+	expectedTree := gal.Tree{
+		gal.NewObjectProperty("order", "Customer"),
+		gal.OptionalDotVariable{gal.DotVariable{gal.NewVariable("Address")}},
+		gal.DotVariable{gal.NewVariable("City")},
+	}
+
+	require.Equal(t, expectedTree, parsedExpr)
+
+	expectedTree = gal.Tree{
+		gal.NewObjectProperty("order", ""),
+		gal.OptionalDotVariable{gal.DotVariable{gal.NewVariable("Customer")}},
+	}
+	require.Equal(t, expectedTree, gal.Parse(`order?.Customer`))
+
+	noCustomer := &Order{ID: "1"}
+	noAddress := &Order{ID: "2", Customer: &Customer{Name: "Bob"}}
+	full := &Order{ID: "3", Customer: &Customer{Name: "Alice", Address: &Address{City: "Paris"}, loyalty: &Loyalty{tier: "gold"}}}
+	withItems := &Order{ID: "4", ItemPtrs: []*Item{{Name: "pen", Total: 2}, nil}}
+
+	testCases := map[string]struct {
+		expr  string
+		order *Order
+		want  string
+	}{
+		"nil receiver skips the chain":     {expr: `order.Customer?.Address.City`, order: noCustomer, want: "Null"},
+		"nil receiver with default":        {expr: `order.Customer?.Address.City ?? "unknown"`, order: noCustomer, want: `"unknown"`},
+		"nil property":                     {expr: `order.Customer?.Address?.City ?? "unknown"`, order: noAddress, want: `"unknown"`},
+		"nil property is Null":             {expr: `order.Customer.Address == Null`, order: noAddress, want: "True"},
+		"set properties":                   {expr: `order.Customer?.Address?.City`, order: full, want: `"Paris"`},
+		"nil method receiver":              {expr: `order.Customer?.Loyalty()?.Tier()`, order: noCustomer, want: "Null"},
+		"nil method result":                {expr: `order.Customer?.Loyalty()?.Tier()`, order: noAddress, want: "Null"},
+		"set method result":                {expr: `order.Customer?.Loyalty()?.Tier()`, order: full, want: `"gold"`},
+		"nil receiver without ?.":          {expr: `order.Customer.Address`, order: noCustomer, want: "undefined: error: object reference 'order.Customer.Address': property 'Customer' of 'order' is nil (use 'order.Customer?.Address' if it is optional)"},
+		"nil method receiver without ?.":   {expr: `order.Customer.Loyalty()`, order: noCustomer, want: "undefined: error: object reference 'order.Customer.Loyalty': property 'Customer' of 'order' is nil (use 'order.Customer?.Loyalty' if it is optional)"},
+		"optional chain in an expression":  {expr: `order.Customer?.Name + "!"`, order: noAddress, want: `"Bob!"`},
+		"Null in an arithmetic expression": {expr: `order.Customer?.Name + "!"`, order: noCustomer, want: `undefined: operator '+' cannot be applied to Null: 'Null' + '"!"'`},
+		"object value":                     {expr: `order.Customer`, order: noAddress, want: "ObjectValue(*gal_test.Customer)"},
+		"object coalesce set":              {expr: `order.Customer ?? "none"`, order: noAddress, want: "ObjectValue(*gal_test.Customer)"},
+		"object coalesce nil":              {expr: `order.Customer ?? "none"`, order: noCustomer, want: `"none"`},
+		"object is not Null":               {expr: `order.Customer == Null`, order: noAddress, want: "False"},
+		"optional object is not Null":      {expr: `order.Customer?.Address != Null`, order: full, want: "True"},
+		"nil element":                      {expr: `order.ItemPtrs[1]?.Name`, order: withItems, want: "Null"},
+		"nil element with default":         {expr: `order.ItemPtrs[1]?.Name ?? "none"`, order: withItems, want: `"none"`},
+		"set element":                      {expr: `order.ItemPtrs[0]?.Name`, order: withItems, want: `"pen"`},
+		"nil element in a list":            {expr: `[order.ItemPtrs[1]][0] == Null`, order: withItems, want: "True"},
+		"nil root object":                  {expr: `order?.Customer`, order: nil, want: "Null"},
+		"nil root object with default":     {expr: `order?.Customer?.Name ?? "none"`, order: nil, want: `"none"`},
+		"set root object":                  {expr: `order?.Customer?.Name`, order: noAddress, want: `"Bob"`},
+		"set root object method":           {expr: `order?.Customer.Loyalty()?.Tier()`, order: full, want: `"gold"`},
+		"Null literal":                     {expr: `Null?.Customer`, order: full, want: "Null"},
+		"Null literal with default":        {expr: `Null?.Customer ?? 1`, order: full, want: "1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithObjects(map[string]gal.Object{"order": tc.order}))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestObjects_Paths(t *testing.T) {
+	expr := `order.Customer.Address.City + order.Customer.Loyalty().Tier()`
+	parsedExpr := gal.Parse(expr)
+
+	expectedTree := gal.Tree{
+		gal.NewObjectProperty("order", "Customer.Address.City"),
+		gal.Plus,
+		gal.NewObjectMethod("order", "Customer.Loyalty", []gal.Tree{}...),
+		gal.DotFunction{gal.NewFunction("Tier", nil, []gal.Tree{}...)},
+	}
+
+	require.Equal(t, expectedTree, parsedExpr)
+
+	objects := gal.WithObjects(map[string]gal.Object{
+		"order": &Order{ID: "3", Customer: &Customer{Name: "Alice", Address: &Address{City: "Paris"}, loyalty: &Loyalty{tier: "gold"}}},
+		"guest": &Order{ID: "4", Customer: &Customer{Name: "Bob"}},
+	})
+
+	got := parsedExpr.Eval(objects)
+	assert.Equal(t, `"Parisgold"`, got.String())
+
+	// a method referenced by a Function, at any depth
+	got = gal.Tree{
+		gal.NewFunction("order.Customer.Loyalty", nil),
+		gal.DotFunction{gal.NewFunction("Tier", nil)},
+	}.Eval(objects)
+	assert.Equal(t, `"gold"`, got.String())
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"unknown property": {expr: `order.Customer.Adress.City`, want: "undefined: error: object reference 'order.Customer.Adress.City': property 'Adress' of 'order.Customer': property '*gal_test.Customer:Adress' does not exist on object"},
+		"unknown object":   {expr: `shop.Customer.Name`, want: "undefined: error: object reference 'shop.Customer.Name': unknown object 'shop'"},
+		"unknown method":   {expr: `order.Customer.Fidelity()`, want: "undefined: error: object 'order.Customer' method 'Fidelity': unknown or non-callable member (check if it has a pointer receiver)"},
+		"nil property":     {expr: `guest.Customer.Address.City`, want: "undefined: error: object reference 'guest.Customer.Address.City': property 'Address' of 'guest.Customer' is nil (use 'guest.Customer.Address?.City' if it is optional)"},
+		"nil method value": {expr: `guest.Customer.Loyalty().Tier()`, want: "undefined: error: DotFunction called on a nil object: [member: 'Tier'] (use '?.Tier' if the object is optional)"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(objects)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
 func TestObjects_Properties_TwoObjects(t *testing.T) {
 	expr := `Road.Type == "Highway"
 	And Car.IsRunning()
@@ -645,39 +1136,572 @@ func TestObjects_MethodReceiver(t *testing.T) {
 	assert.Equal(t, "undefined: error: object 'aCar' method 'CurrentSpeed': unknown or non-callable member (check if it has a pointer receiver)", got.String())
 }
 
-// TODO: this is an idea for a future feature.
-// func TestObjects_Collections(t *testing.T) {
-//	expr := `aCar.Tyres[0].Age - aCar.Drivers["Bob"].Age`
-//	parsedExpr := gal.Parse(expr)
-
-//	got := parsedExpr.Eval(
-//		gal.WithObjects(map[string]gal.Object{
-//			"aCar": &Car{
-//				Make:            "Lotus Esprit",
-//				Mileage:         gal.NewNumberFromInt(2000),
-//				Speed:           100,
-//				MaxSpeed:        250,
-//				ComplexProperty: 0,
-//				Tyres: []Tyre{
-//					{
-//						Location: "Front-Left",
-//						Age:      5,
-//					},
-//					{
-//						Location: "Front-Right",
-//						Age:      1,
-//					},
-//				},
-//				Drivers: map[string]Driver{
-//					"Bob": {
-//						Age: 32,
-//					},
-//					"Alice": {
-//						Age: 37,
-//					},
-//				},
-//			},
-//		}),
-//	)
-//	assert.Equal(t, "-27", got.String())
-// }
+func TestObjects_Collections(t *testing.T) {
+	expr := `aCar.Tyres[0].Age - aCar.Drivers["Bob"].Age`
+	parsedExpr := gal.Parse(expr)
+
+	objects := gal.WithObjects(map[string]gal.Object{
+		"aCar": &Car{
+			Make:            "Lotus Esprit",
+			Mileage:         gal.NewNumberFromInt(2000),
+			Speed:           100,
+			MaxSpeed:        250,
+			ComplexProperty: 0,
+			Tyres: []Tyre{
+				{
+					Location: "Front-Left",
+					Age:      5,
+				},
+				{
+					Location: "Front-Right",
+					Age:      1,
+				},
+			},
+			Drivers: map[string]Driver{
+				"Bob": {
+					Age: 32,
+				},
+				"Alice": {
+					Age: 37,
+				},
+			},
+		},
+	})
+
+	got := parsedExpr.Eval(objects)
+	assert.Equal(t, "-27", got.String())
+
+	got = gal.Parse(`aCar.Tyres[-1].Location`).Eval(objects)
+	assert.Equal(t, `"Front-Right"`, got.String())
+
+	got = gal.Parse(`aCar.Tyres[1:].Size()`).Eval(objects)
+	assert.Equal(t, "1", got.String())
+
+	got = gal.Parse(`aCar.Drivers["Eve"]`).Eval(objects)
+	assert.Equal(t, `undefined: index accessor '[Value gal.String "Eve"]': key 'Eve' does not exist in map`, got.String())
+
+	got = gal.Parse(`aCar.Drivers.Alice.Age`).Eval(objects)
+	assert.Equal(t, "37", got.String())
+
+	got = gal.Parse(`aCar.Drivers.Eve`).Eval(objects)
+	assert.Equal(t, "undefined: error: object reference 'aCar.Drivers.Eve': property 'Eve' of 'aCar.Drivers': key 'Eve' does not exist in map", got.String())
+
+	// only the element that is accessed is converted: the other ones may not map to a gal.Value
+	got = gal.Parse(`:m:.a + 1`).Eval(gal.WithVariables(gal.Variables{
+		":m:": gal.ObjectValue{Object: map[string]any{"a": 1, "b": make(chan int)}},
+	}))
+	assert.Equal(t, "2", got.String())
+}
+
+func TestEval_LogicalShortCircuit(t *testing.T) {
+	calls := 0
+	funcs := gal.Functions{
+		"expensiveCheck": func(args ...gal.Value) gal.Value {
+			calls++
+			if len(args) != 1 {
+				return gal.NewUndefinedWithReasonf("expensiveCheck() requires a single argument, got %d", len(args))
+			}
+			return args[0].(gal.String).EqualTo(gal.NewString("bob"))
+		},
+	}
+
+	expr := `:user: != "" And expensiveCheck(:user:)`
+	parsedExpr := gal.Parse(expr)
+
+	got := parsedExpr.Eval(
+		gal.WithVariables(gal.Variables{":user:": gal.NewString("")}),
+		gal.WithFunctions(funcs),
+	)
+	assert.Equal(t, gal.False, got)
+	assert.Equal(t, 0, calls)
+
+	got = parsedExpr.Eval(
+		gal.WithVariables(gal.Variables{":user:": gal.NewString("bob")}),
+		gal.WithFunctions(funcs),
+	)
+	assert.Equal(t, gal.True, got)
+	assert.Equal(t, 1, calls)
+
+	tt := map[string]struct {
+		expr string
+		want string
+	}{
+		"And with a False lhs skips the function":      {expr: `False && unknownFunc()`, want: "False"},
+		"Or with a True lhs skips the function":        {expr: `1 < 2 || unknownFunc()`, want: "True"},
+		"Or with a True lhs skips the sub-tree":        {expr: `True Or (1 + unknownFunc() > 2)`, want: "True"},
+		"And with a False lhs skips the object method": {expr: `2 == 3 And aCar.Unknown()`, want: "False"},
+		"And with a False lhs skips the variable":      {expr: `False And :unknown:`, want: "False"},
+		"left to right evaluation":                     {expr: `False And unknownFunc() Or True`, want: "True"},
+		"nested short-circuit":                         {expr: `(True Or unknownFunc()) And (False And unknownFunc())`, want: "False"},
+		"rhs is evaluated when lhs does not decide":    {expr: `True And unknownFunc()`, want: "undefined: error: unknown user-defined function 'unknownFunc'"},
+		"missing lhs": {expr: `And True`, want: "undefined: syntax error: missing left hand side value for operator 'And'"},
+		"missing rhs": {expr: `True Or`, want: "undefined: syntax error: missing right hand side value for operator 'Or'"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(
+				gal.WithObjects(map[string]gal.Object{
+					"aCar": &Car{},
+				}),
+			)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_Conditional(t *testing.T) {
+	expr := `if(:qty: > 100 :price: * 0.9 :price:)`
+	parsedExpr := gal.Parse(expr)
+
+	expectedTree := gal.Tree{
+		gal.NewConditional(
+			gal.Tree{gal.NewVariable(":qty:"), gal.GreaterThan, gal.NewNumberFromInt(100)},
+			gal.Tree{gal.NewVariable(":price:"), gal.Multiply, gal.NewNumber(9, -1)},
+			gal.Tree{gal.NewVariable(":price:")},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, parsedExpr) {
+		t.Error(cmp.Diff(expectedTree, parsedExpr))
+		t.FailNow()
+	}
+
+	got := parsedExpr.Eval(gal.WithVariables(gal.Variables{
+		":qty:":   gal.NewNumberFromInt(150),
+		":price:": gal.NewNumberFromInt(20),
+	}))
+	assert.Equal(t, "18", got.String())
+
+	got = parsedExpr.Eval(gal.WithVariables(gal.Variables{
+		":qty:":   gal.NewNumberFromInt(50),
+		":price:": gal.NewNumberFromInt(20),
+	}))
+	assert.Equal(t, "20", got.String())
+
+	// only the selected branch is evaluated.
+	var calls []string
+	funcs := gal.Functions{
+		"yes": func(...gal.Value) gal.Value { calls = append(calls, "yes"); return gal.NewString("yes") },
+		"no":  func(...gal.Value) gal.Value { calls = append(calls, "no"); return gal.NewString("no") },
+	}
+
+	got = gal.Parse(`"answer: " + IF(1 < 2 yes() no())`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, `"answer: yes"`, got.String())
+	assert.Equal(t, []string{"yes"}, calls)
+
+	got = gal.Parse(`if(:x: != 0 10 / :x: 0)`).Eval(gal.WithVariables(gal.Variables{":x:": gal.NewNumberFromInt(0)}))
+	assert.Equal(t, "0", got.String())
+
+	got = gal.Parse(`if("abc" 1 2)`).Eval()
+	assert.Equal(t, `undefined: if(): condition must be a Bool, got '"abc"'`, got.String())
+
+	_, err := gal.ParseE(`1 + if(True 2)`)
+	assert.EqualError(t, err, "syntax error: if() requires 3 arguments (condition, then, else), got 2 (line 1, column 5)")
+}
+
+func TestEval_UnaryOperators(t *testing.T) {
+	tt := map[string]struct {
+		expr string
+		want string
+	}{
+		"minus after multiply":                  {expr: `2 * -3`, want: "-6"},
+		"minus after power":                     {expr: `:a: ** -1`, want: "0.25"},
+		"minus binds looser than power":         {expr: `2 * -3 ** 2`, want: "-18"},
+		"leading minus and power":               {expr: `-2 ** 2`, want: "-4"},
+		"power of a negative power":             {expr: `2 ** -:a: ** 2`, want: "0.0000152587890625"},
+		"plus after divide":                     {expr: `12 / +:a:`, want: "3"},
+		"minus after comparison":                {expr: `-5 < -:a:`, want: "True"},
+		"minus on sub-tree":                     {expr: `2 * -(:a: + 1)`, want: "-10"},
+		"minus on dot accessor chain":           {expr: `10 * -:a:.Add(1)`, want: "-50"},
+		"not":                                   {expr: `!True`, want: "False"},
+		"worded not":                            {expr: `Not False`, want: "True"},
+		"not on sub-tree":                       {expr: `!(1 > 2)`, want: "True"},
+		"double not":                            {expr: `!!True`, want: "True"},
+		"not in logical expression":             {expr: `!False And Not (1 > 2)`, want: "True"},
+		"not on a number":                       {expr: `!0`, want: "True"},
+		"not on a string":                       {expr: `!"abc"`, want: `undefined: syntax error: operator '!' requires a Bool, got '"abc"'`},
+		"not without operand":                   {expr: `!`, want: "undefined: syntax error: missing operand for operator '!'"},
+		"not equal is not a not":                {expr: `1 != 2`, want: "True"},
+		"glued minus starts a new argument":     {expr: `count(1 -:a:)`, want: "2"},
+		"spaced minus is a binary operator":     {expr: `count(1 - :a:)`, want: "1"},
+		"glued minus in first argument":         {expr: `sum(-1 -:a:)`, want: "-5"},
+		"glued minus before a number":           {expr: `ediv(7 -2)`, want: "-3"},
+		"glued minus in list literal":           {expr: `[1 -2]`, want: "1,-2"},
+		"minus chain is a binary operator":      {expr: `count(1 - -:a:)`, want: "1"},
+		"glued minus outside function argument": {expr: `1 -:a:`, want: "-3"},
+	}
+
+	funcs := gal.Functions{
+		"count": func(args ...gal.Value) gal.Value {
+			return gal.NewNumberFromInt(int64(len(args)))
+		},
+		"sum": func(args ...gal.Value) gal.Value {
+			var total gal.Value = gal.NewNumberFromInt(0)
+			for _, a := range args {
+				total = total.Add(a)
+			}
+			return total
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(
+				gal.WithVariables(gal.Variables{":a:": gal.NewNumberFromInt(4)}),
+				gal.WithFunctions(funcs),
+			)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_ListLiteral(t *testing.T) {
+	vars := gal.Variables{
+		":v:": gal.NewString("GB"),
+	}
+
+	got := gal.Parse(`[1 + 1 "FR" :v: [3 [4]] []]`).Eval(gal.WithVariables(vars))
+	expected := gal.NewMultiValue(
+		gal.NewNumberFromInt(2),
+		gal.NewString("FR"),
+		gal.NewString("GB"),
+		gal.NewMultiValue(
+			gal.NewNumberFromInt(3),
+			gal.NewMultiValue(gal.NewNumberFromInt(4)),
+		),
+		gal.NewMultiValue(),
+	)
+	assert.Equal(t, expected.String(), got.String())
+	assert.True(t, expected.Equal(got.(gal.MultiValue)))
+
+	// elements are evaluated at Eval time.
+	parsedExpr := gal.Parse(`[:v: "FR"]`)
+	got = parsedExpr.Eval(gal.WithVariables(gal.Variables{":v:": gal.NewString("DE")}))
+	assert.Equal(t, `"DE","FR"`, got.String())
+
+	got = gal.Parse(`f([1 2 3])`).Eval(gal.WithFunctions(gal.Functions{
+		"f": func(args ...gal.Value) gal.Value {
+			return gal.NewNumberFromInt(int64(args[0].(gal.MultiValue).Size()))
+		},
+	}))
+	assert.Equal(t, "3", got.String())
+
+	got = gal.Parse(`[1 2] == [1 2]`).Eval()
+	assert.Equal(t, gal.True, got)
+
+	got = gal.Parse(`[1 2] != [2 1]`).Eval()
+	assert.Equal(t, gal.True, got)
+
+	got = gal.Parse(`[1 :unknown:]`).Eval()
+	assert.Equal(t, "undefined: error: unknown user-defined variable ':unknown:'", got.String())
+}
+
+func TestEval_IndexAccessor(t *testing.T) {
+	vars := gal.Variables{
+		":list:": gal.NewMultiValue(gal.NewNumberFromInt(10), gal.NewNumberFromInt(20), gal.NewNumberFromInt(30), gal.NewNumberFromInt(40)),
+		":m:":    gal.ObjectValue{Object: map[string]int{"key": 7}},
+		":im:":   gal.ObjectValue{Object: map[int]string{1: "one"}},
+		":um:":   gal.ObjectValue{Object: map[uint8]string{1: "one"}},
+		":i:":    gal.NewNumberFromInt(1),
+	}
+	funcs := gal.Functions{
+		"f": func(args ...gal.Value) gal.Value { return gal.NewMultiValue(args...) },
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"variable":                 {expr: `:list:[0]`, want: "10"},
+		"negative index":           {expr: `:list:[-1]`, want: "40"},
+		"index expression":         {expr: `:list:[:i: + 1]`, want: "30"},
+		"function":                 {expr: `f(1 2 3)[2]`, want: "3"},
+		"string":                   {expr: `"hello"[1]`, want: `"e"`},
+		"string range":             {expr: `"hello"[1:3]`, want: `"el"`},
+		"string negative range":    {expr: `"hello"[-3:]`, want: `"llo"`},
+		"map":                      {expr: `:m:["key"]`, want: "7"},
+		"range":                    {expr: `:list:[1:3]`, want: "20,30"},
+		"range without low":        {expr: `:list:[:2]`, want: "10,20"},
+		"range with variable":      {expr: `:list:[:i: : -1]`, want: "20,30"},
+		"list literal":             {expr: `[1 [2 3]][1][0]`, want: "2"},
+		"operator precedence":      {expr: `2 * :list:[0] + 1`, want: "21"},
+		"power operator":           {expr: `2 ** f(1 2)[1]`, want: "4"},
+		"unary minus":              {expr: `-:list:[1]`, want: "-20"},
+		"chain with DotFunction":   {expr: `:list:[0].Add(5)`, want: "15"},
+		"function argument":        {expr: `f(:list:[0] 2).Size()`, want: "2"},
+		"out of range":             {expr: `:list:[4]`, want: "undefined: index out of range: index 4 with length 4"},
+		"negative out of range":    {expr: `:list:[-5]`, want: "undefined: index out of range: index -5 with length 4"},
+		"range out of range":       {expr: `:list:[2:5]`, want: "undefined: slice bounds out of range: [2:5] with length 4"},
+		"inverted range":           {expr: `:list:[3:1]`, want: "undefined: slice bounds out of range: [3:1] with length 4"},
+		"non-integer index":        {expr: `:list:[1.5]`, want: "undefined: index must be an integer, got '1.5'"},
+		"huge index":               {expr: `[1 2 3][18446744073709551616]`, want: "undefined: index out of range: index 18446744073709551616"},
+		"huge range bound":         {expr: `[1 2 3][1:18446744073709551616]`, want: "undefined: index out of range: index 18446744073709551616"},
+		"integer map key":          {expr: `:im:[1]`, want: `"one"`},
+		"non-integer map key":      {expr: `:im:[1.7]`, want: "undefined: index accessor '[Value gal.Number 1.7]': value '1.7' is not an integer and cannot be converted to Go type 'int'"},
+		"overflowing map key":      {expr: `:um:[256]`, want: "undefined: index accessor '[Value gal.Number 256]': value '256' overflows Go type 'uint8'"},
+		"non-number index":         {expr: `:list:["a"]`, want: `undefined: index must be a Number, got '"a"'`},
+		"unknown map key":          {expr: `:m:["nope"]`, want: `undefined: index accessor '[Value gal.String "nope"]': key 'nope' does not exist in map`},
+		"value cannot be indexed":  {expr: `:i:[0]`, want: "undefined: index accessor '[Value gal.Number 0]': value of type 'gal.Number' cannot be indexed"},
+		"undefined index":          {expr: `:list:[:nope:]`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+		"blank before the bracket": {expr: `f(:i: [1])`, want: "1,1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars), gal.WithFunctions(funcs))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_Map(t *testing.T) {
+	payload := map[string]any{
+		"user": map[string]any{
+			"name":  "bob",
+			"age":   32,
+			"roles": []any{"admin", "dev"},
+		},
+		"country": "GB",
+	}
+
+	m, err := gal.NewMapFromGo(payload)
+	require.NoError(t, err)
+
+	vars := gal.Variables{
+		":x:":       gal.NewNumberFromInt(5),
+		":payload:": m,
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"literal":             {expr: `{"b": :x:, "a": 1 + 1}`, want: `{"b": 5, "a": 2}`},
+		"empty literal":       {expr: `{}`, want: `{}`},
+		"dot access":          {expr: `{"a": 1, "b": 2}.b`, want: "2"},
+		"bracket access":      {expr: `{"a": 1, "b": 2}["a"]`, want: "1"},
+		"nested access":       {expr: `:payload:.user.name + " from " + :payload:["country"]`, want: `"bob from GB"`},
+		"nested list":         {expr: `:payload:.user.roles[-1]`, want: `"dev"`},
+		"arithmetic":          {expr: `:payload:.user["age"] + 1`, want: "33"},
+		"equal":               {expr: `{"a": 1, "b": [1 2]} == {"b": [1 2], "a": 1}`, want: "True"},
+		"not equal":           {expr: `{"a": 1} != {"a": 2}`, want: "True"},
+		"different keys":      {expr: `{"a": 1} == {"b": 1}`, want: "False"},
+		"unknown key by dot":  {expr: `:payload:.nope`, want: "undefined: key 'nope' does not exist in map"},
+		"unknown key by []":   {expr: `:payload:["nope"]`, want: `undefined: index accessor '[Value gal.String "nope"]': key 'nope' does not exist in map`},
+		"non-string key":      {expr: `:payload:[1]`, want: "undefined: index accessor '[Value gal.Number 1]': map key must be a String, got '1'"},
+		"undefined value":     {expr: `{"a": :nope:}`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+		"method on map value": {expr: `:payload:.Size()`, want: "2"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// Go maps held by objects are Map's too.
+	got := gal.Parse(`payload.country`).Eval(gal.WithObjects(gal.Objects{"payload": payload}))
+	assert.Equal(t, `"GB"`, got.String())
+
+	// and back to Go.
+	got = gal.Parse(`{"a": 1, "b": 1.5, "c": [True "x"], "d": {"e": "f"}}`).Eval()
+	assert.Equal(t,
+		map[string]any{"a": int64(1), "b": 1.5, "c": []any{true, "x"}, "d": map[string]any{"e": "f"}},
+		got.(gal.Map).ToGoMap(),
+	)
+}
+
+func TestEval_Lambda(t *testing.T) {
+	type order struct {
+		Total int
+	}
+
+	vars := gal.Variables{
+		":items:": gal.NewMultiValue(gal.NewNumberFromInt(3), gal.NewNumberFromInt(1), gal.NewNumberFromInt(2)),
+		":orders:": gal.NewMultiValue(
+			gal.ObjectValue{Object: order{Total: 50}},
+			gal.ObjectValue{Object: order{Total: 150}},
+			gal.ObjectValue{Object: order{Total: 250}},
+		),
+		":factor:": gal.NewNumberFromInt(10),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"map":                 {expr: `map(:items: x -> x * 2)`, want: "6,2,4"},
+		"map with variable":   {expr: `map(:items: x -> x * :factor:)`, want: "30,10,20"},
+		"filter on objects":   {expr: `filter(:orders: o -> o.Total > 100).Size()`, want: "2"},
+		"reduce":              {expr: `reduce(:items: (acc x) -> acc + x 0)`, want: "6"},
+		"reduce on objects":   {expr: `reduce(filter(:orders: o -> o.Total > 100) (acc o) -> acc + o.Total 0)`, want: "400"},
+		"any":                 {expr: `any(:items: x -> x > 2)`, want: "True"},
+		"all":                 {expr: `all(:items: x -> x > 0)`, want: "True"},
+		"sortBy":              {expr: `sortBy(:items: x -> -x)`, want: "3,2,1"},
+		"sortBy and index":    {expr: `sortBy(:orders: o -> -o.Total)[0].Total`, want: "250"},
+		"closure":             {expr: `map(:items: x -> reduce(:items: (acc y) -> acc + x * y 0))`, want: "18,6,12"},
+		"list literal":        {expr: `map([1 2 3] x -> [x x ** 2][-1])`, want: "1,4,9"},
+		"map literal":         {expr: `map(:items: x -> {"v": x}.v + 1)`, want: "4,2,3"},
+		"conditional":         {expr: `map(:items: x -> if(x > 1 "big" "small"))`, want: `"big","small","big"`},
+		"method on parameter": {expr: `map(:items: x -> x.Add(1))`, want: "4,2,3"},
+		"lambda value":        {expr: `filter(:items: (x) -> x != 1)`, want: "3,2"},
+		"undefined in body":   {expr: `map(:items: x -> x + :nope:)`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+		"not a lambda":        {expr: `map(:items: 1)`, want: "undefined: map(): argument #2 must be a lambda, got '1'"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// a lambda can be called from Go.
+	got := gal.Parse(`(a b) -> a * b + :factor:`).Eval(gal.WithVariables(vars))
+	fn, ok := got.(gal.Lambda)
+	require.True(t, ok)
+	assert.Equal(t, "16", fn.FunctionalValue()(gal.NewNumberFromInt(2), gal.NewNumberFromInt(3)).String())
+
+	// a Go slice held in an object property is a collection, like a MultiValue.
+	objects := gal.WithObjects(gal.Objects{
+		"order": &Order{ID: "1", Items: []Item{{Name: "pen", Total: 50}, {Name: "ink", Total: 150}, {Name: "pad", Total: 250}}},
+	})
+
+	testCases = map[string]struct {
+		expr string
+		want string
+	}{
+		"map over a slice":    {expr: `map(order.Items o -> o.Name)`, want: `"pen","ink","pad"`},
+		"filter over a slice": {expr: `filter(order.Items o -> o.Total > 100).Size()`, want: "2"},
+		"reduce over a slice": {expr: `reduce(order.Items (acc o) -> acc + o.Total 0)`, want: "450"},
+		"not a collection":    {expr: `map(order.ID x -> x)`, want: `undefined: map(): argument #1 must be a MultiValue, got '"1"'`},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(objects)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_Let(t *testing.T) {
+	vars := gal.Variables{
+		":gross:": gal.NewNumberFromInt(1000),
+		":tax:":   gal.NewNumberFromInt(200),
+		":items:": gal.NewMultiValue(gal.NewNumberFromInt(1), gal.NewNumberFromInt(2)),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"simple":          {expr: `let net = :gross: - :tax: in net * 0.2 + net * 0.05`, want: "200"},
+		"nested in body":  {expr: `let a = 1 in let b = a + 1 in a + b`, want: "3"},
+		"nested in value": {expr: `let a = let b = 2 in b * 3 in a + 1`, want: "7"},
+		"operand":         {expr: `1 + let a = 2 in a * 10`, want: "21"},
+		"shadowing":       {expr: `let a = 1 in a + (let a = 5 in a) + a`, want: "7"},
+		"in a lambda":     {expr: `map(:items: x -> let y = x * 10 in y + 1)`, want: "11,21"},
+		"closure":         {expr: `let k = 3 in map(:items: x -> x * k)`, want: "3,6"},
+		"lambda binding":  {expr: `let double = x -> x * 2 in double(21)`, want: "42"},
+		"undefined value": {expr: `let a = :nope: in a`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// the binding is evaluated once.
+	calls := 0
+	funcs := gal.Functions{
+		"expensive": func(...gal.Value) gal.Value {
+			calls++
+			return gal.NewNumberFromInt(10)
+		},
+	}
+
+	got := gal.Parse(`let v = expensive() in v * v + v`).Eval(gal.WithFunctions(funcs))
+	assert.Equal(t, "110", got.String())
+	assert.Equal(t, 1, calls)
+
+	// the binding does not leak into the caller's variables.
+	assert.Len(t, vars, 3)
+}
+
+func TestEval_Comments(t *testing.T) {
+	vars := gal.Variables{
+		":x:": gal.NewNumberFromInt(3),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"line comment":        {expr: "2 * :x: // double it", want: "6"},
+		"block comment":       {expr: "2 /* two */ ** /* three */ 3", want: "8"},
+		"divide and multiply": {expr: "10/2*3 // 10 / 2 * 3", want: "15"},
+		"script":              {expr: "a = :x: + 1; // a is 4\n/* a squared */ a * a", want: "16"},
+		"only a comment":      {expr: "// nothing to see", want: "undefined: syntax error: empty expression"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func TestEval_Block(t *testing.T) {
+	vars := gal.Variables{
+		":x:":     gal.NewNumberFromInt(3),
+		":y:":     gal.NewNumberFromInt(16),
+		":items:": gal.NewMultiValue(gal.NewNumberFromInt(1), gal.NewNumberFromInt(2)),
+	}
+
+	testCases := map[string]struct {
+		expr string
+		want string
+	}{
+		"script":             {expr: `a = :x: * 2; b = a + sqrt(:y:); a / b`, want: "0.6"},
+		"reassignment":       {expr: `a = 1; a = a + 1; a * 10`, want: "20"},
+		"trailing separator": {expr: `a = 2; a ** 3;`, want: "8"},
+		"assignment last":    {expr: `a = 2; b = a * 4`, want: "8"},
+		"user variable":      {expr: `:x: = :x: + 1; :x: * 2`, want: "8"},
+		"in a group":         {expr: `(a = 2; a * a) + 1`, want: "5"},
+		"lambda":             {expr: `double = x -> x * 2; double(21)`, want: "42"},
+		"closure":            {expr: `k = 3; map(:items: x -> x * k)`, want: "3,6"},
+		"string separator":   {expr: `s = "a;b"; s + "c"`, want: `"a;bc"`},
+		"undefined":          {expr: `a = :nope:; 1`, want: "undefined: error: unknown user-defined variable ':nope:'"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := gal.Parse(tc.expr).Eval(gal.WithVariables(vars))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+
+	// assignments do not mutate the caller's variables by default.
+	assert.Equal(t, "3", vars[":x:"].String())
+	assert.Len(t, vars, 3)
+
+	// unless explicitly enabled.
+	got := gal.Parse(`a = 10; :x: = :x: + a; :total: = :x: * 2; :total:`).Eval(gal.WithVariables(vars), gal.WithMutableVariables())
+	assert.Equal(t, "26", got.String())
+	assert.Equal(t, "13", vars[":x:"].String())
+	assert.Equal(t, "26", vars[":total:"].String())
+	assert.NotContains(t, vars, "a")
+
+	// the empty statements of a Block built by hand are ignored
+	got = gal.Tree{gal.NewBlock(gal.Tree{}, gal.Tree{gal.NewNumberFromInt(1)}, gal.Tree{})}.Eval()
+	assert.Equal(t, "1", got.String())
+
+	got = gal.Tree{gal.NewBlock(gal.Tree{})}.Eval()
+	assert.Equal(t, "undefined: syntax error: empty script", got.String())
+}
@@ -0,0 +1,251 @@
+package gal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// IndexAccessor is a Tree entry that accesses an element of the value held in the
+// "left hand side" expression, such as `:list:[0]`, `f()[2]`, `"hello"[1]` or `:m:["key"]`.
+// When IsRange is set, it accesses a slice of the value instead, such as `:list:[1:3]`.
+// Low and High are nil when the bound is omitted (e.g. `[:3]` or `[1:]`).
+//
+// Negative indices count from the end of the value i.e. `[-1]` is the last element.
+type IndexAccessor struct {
+	Low     Tree
+	High    Tree
+	IsRange bool
+}
+
+func NewIndexAccessor(index Tree) IndexAccessor {
+	return IndexAccessor{Low: index}
+}
+
+func NewRangeIndexAccessor(low, high Tree) IndexAccessor {
+	return IndexAccessor{
+		Low:     low,
+		High:    high,
+		IsRange: true,
+	}
+}
+
+func (ia IndexAccessor) Calculate(val entry, cfg *treeConfig) entry {
+	// as this is an accessor, we need to get the indexed value first: it is the LHS currently held in val
+	receiver, ok := val.(Value)
+	if !ok {
+		return NewUndefinedWithReasonf("syntax error: index accessor '%s' called without a value (check if the receiver is nil)", ia.String())
+	}
+
+	low, high := ia.bounds(cfg)
+	if u, ok := low.(Undefined); ok {
+		return u
+	}
+	if u, ok := high.(Undefined); ok {
+		return u
+	}
+
+	switch typedR := receiver.(type) {
+	case MultiValue:
+		if ia.IsRange {
+			from, to, u := sliceBounds(low, high, typedR.Size())
+			if u != nil {
+				return u
+			}
+			return NewMultiValue(append([]Value{}, typedR.values[from:to]...)...)
+		}
+
+		i, u := elementIndex(low, typedR.Size())
+		if u != nil {
+			return u
+		}
+		return typedR.values[i]
+
+	case String:
+		runes := []rune(typedR.RawString())
+		if ia.IsRange {
+			from, to, u := sliceBounds(low, high, len(runes))
+			if u != nil {
+				return u
+			}
+			return NewString(string(runes[from:to]))
+		}
+
+		i, u := elementIndex(low, len(runes))
+		if u != nil {
+			return u
+		}
+		return NewString(string(runes[i]))
+
+	case Map:
+		if ia.IsRange {
+			return NewUndefinedWithReasonf("index accessor '%s': a map cannot be sliced", ia.String())
+		}
+
+		key, ok := low.(String)
+		if !ok {
+			return NewUndefinedWithReasonf("index accessor '%s': map key must be a String, got '%s'", ia.String(), low.String())
+		}
+
+		v := typedR.Get(key.RawString())
+		if u, ok := v.(Undefined); ok {
+			return NewUndefinedWithReasonf("index accessor '%s': %s", ia.String(), u.reason)
+		}
+		return v
+
+	case ObjectValue:
+		return ia.indexObject(typedR.Object, low, high)
+
+	default:
+		return NewUndefinedWithReasonf("index accessor '%s': value of type '%T' cannot be indexed", ia.String(), receiver)
+	}
+}
+
+// bounds evaluates the bounds of the index accessor.
+// A bound that is omitted is returned as nil.
+func (ia IndexAccessor) bounds(cfg *treeConfig) (Value, Value) {
+	var low, high Value
+
+	if ia.Low != nil {
+		low = ia.Low.Eval(cfg.options()...)
+	}
+
+	if ia.High != nil {
+		high = ia.High.Eval(cfg.options()...)
+	}
+
+	return low, high
+}
+
+// indexObject accesses an element of a Go slice, array or map held in an ObjectValue.
+func (ia IndexAccessor) indexObject(obj any, low, high Value) Value {
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if ia.IsRange {
+			from, to, u := sliceBounds(low, high, v.Len())
+			if u != nil {
+				return u
+			}
+			// NOTE: v.Slice panics on arrays that are not addressable
+			elems := make([]Value, 0, to-from)
+			for i := from; i < to; i++ {
+				elems = append(elems, reflectValueToGalType(v.Index(i)))
+			}
+			return NewMultiValue(elems...)
+		}
+
+		i, u := elementIndex(low, v.Len())
+		if u != nil {
+			return u
+		}
+		return reflectValueToGalType(v.Index(i))
+
+	case reflect.Map:
+		if ia.IsRange {
+			return NewUndefinedWithReasonf("index accessor '%s': a map cannot be sliced", ia.String())
+		}
+
+		key, err := galValueToReflectValue(low, v.Type().Key())
+		if err != nil {
+			return NewUndefinedWithReasonf("index accessor '%s': %s", ia.String(), err.Error())
+		}
+
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return NewUndefinedWithReasonf("index accessor '%s': key '%v' does not exist in map", ia.String(), key.Interface())
+		}
+		return reflectValueToGalType(elem)
+
+	default:
+		return NewUndefinedWithReasonf("index accessor '%s': value of type '%T' cannot be indexed", ia.String(), obj)
+	}
+}
+
+func (ia IndexAccessor) String() string {
+	low := strings.TrimRight(ia.Low.String(), "\n")
+	if !ia.IsRange {
+		return fmt.Sprintf("[%s]", low)
+	}
+
+	high := strings.TrimRight(ia.High.String(), "\n")
+	return fmt.Sprintf("[%s:%s]", low, high)
+}
+
+// elementIndex returns the position of the element designated by index in a value of
+// the specified length.
+// A negative index counts from the end of the value.
+func elementIndex(index Value, length int) (int, Value) {
+	i, u := indexToInt(index)
+	if u != nil {
+		return 0, u
+	}
+
+	if i < 0 {
+		i += length
+	}
+
+	if i < 0 || i >= length {
+		return 0, NewUndefinedWithReasonf("index out of range: index %s with length %d", index.String(), length)
+	}
+
+	return i, nil
+}
+
+// sliceBounds returns the positions designated by low and high in a value of the specified
+// length.
+// A nil bound defaults to the start (low) or the end (high) of the value and negative
+// bounds count from the end of the value.
+func sliceBounds(low, high Value, length int) (int, int, Value) {
+	from, to := 0, length
+
+	if low != nil {
+		i, u := indexToInt(low)
+		if u != nil {
+			return 0, 0, u
+		}
+		from = i
+		if from < 0 {
+			from += length
+		}
+	}
+
+	if high != nil {
+		i, u := indexToInt(high)
+		if u != nil {
+			return 0, 0, u
+		}
+		to = i
+		if to < 0 {
+			to += length
+		}
+	}
+
+	if from < 0 || to > length || from > to {
+		return 0, 0, NewUndefinedWithReasonf("slice bounds out of range: [%d:%d] with length %d", from, to, length)
+	}
+
+	return from, to, nil
+}
+
+func indexToInt(index Value) (int, Value) {
+	n, ok := index.(Number)
+	if !ok {
+		return 0, NewUndefinedWithReasonf("index must be a Number, got '%s'", index.String())
+	}
+
+	if !n.value.IsInteger() {
+		return 0, NewUndefinedWithReasonf("index must be an integer, got '%s'", n.String())
+	}
+
+	// an index that does not fit in an int would wrap around
+	if !n.value.BigInt().IsInt64() || int64(int(n.Int64())) != n.Int64() {
+		return 0, NewUndefinedWithReasonf("index out of range: index %s", n.String())
+	}
+
+	return int(n.Int64()), nil
+}
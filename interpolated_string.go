@@ -0,0 +1,86 @@
+package gal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// InterpolatedString is a Tree entry that holds a string literal with embedded expressions,
+// such as `"Order ${:id:} exceeds ${trunc(:limit: 2)}"`.
+// The embedded expressions are parsed with the rest of the expression and evaluated with
+// the same variables, functions and objects. Their values are converted with AsString().
+type InterpolatedString struct {
+	Segments []StringSegment
+}
+
+// StringSegment is a part of an InterpolatedString: either some text or, when Expr is set,
+// an embedded expression.
+type StringSegment struct {
+	Text string
+	Expr Tree
+}
+
+func NewInterpolatedString(segments ...StringSegment) InterpolatedString {
+	return InterpolatedString{
+		Segments: segments,
+	}
+}
+
+func (is InterpolatedString) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := is.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the embedded expressions and returns the resulting String.
+func (is InterpolatedString) Eval(opts ...treeOption) Value {
+	var sb strings.Builder
+
+	for _, seg := range is.Segments {
+		if seg.Expr == nil {
+			sb.WriteString(seg.Text)
+			continue
+		}
+
+		v := seg.Expr.Eval(opts...)
+		if u, ok := v.(Undefined); ok {
+			return u
+		}
+		sb.WriteString(v.AsString().RawString())
+	}
+
+	return NewString(sb.String())
+}
+
+func (is InterpolatedString) String() string {
+	var sb strings.Builder
+
+	sb.WriteByte('"')
+	for _, seg := range is.Segments {
+		if seg.Expr == nil {
+			q := strconv.Quote(seg.Text)
+			sb.WriteString(escapeInterpolation(q[1 : len(q)-1]))
+			continue
+		}
+		sb.WriteString("${" + strings.TrimRight(seg.Expr.String(), "\n") + "}")
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+// escapeInterpolation escapes the "${" of the quoted string s so that they are not parsed as
+// embedded expressions.
+func escapeInterpolation(s string) string {
+	return strings.ReplaceAll(s, "${", `\${`)
+}
@@ -0,0 +1,93 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Lambda is an anonymous function such as `x -> x * 2` or `(acc x) -> acc + x`.
+// Its parameters are referred to by their bare name in its body.
+//
+// A Lambda is a Value: it can be passed to functions, such as the built-in map(),
+// filter() or reduce(), that call it with Call.
+// It captures the variables, functions and objects in effect where it is evaluated.
+type Lambda struct {
+	Undefined
+	Params []string
+	Body   Tree
+	cfg    *treeConfig
+}
+
+func NewLambda(params []string, body Tree) Lambda {
+	return Lambda{
+		Params: params,
+		Body:   body,
+	}
+}
+
+func (l Lambda) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	// capture the configuration in effect
+	l.cfg = cfg
+
+	if val == nil {
+		return l
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, l)
+
+	return val
+}
+
+// Call evaluates the body of the lambda with its parameters bound to args.
+func (l Lambda) Call(args ...Value) Value {
+	if len(args) != len(l.Params) {
+		return NewUndefinedWithReasonf("lambda '%s' requires %d argument(s), got %d", l.String(), len(l.Params), len(args))
+	}
+
+	cfg := l.cfg
+	if cfg == nil {
+		cfg = &treeConfig{}
+	}
+
+	vars := make(Variables, len(l.Params))
+	for i, p := range l.Params {
+		vars[p] = args[i]
+	}
+
+	return l.Body.Eval(append(cfg.options(), withScope(cfg.scope.push(vars)))...)
+}
+
+// parameters returns the parameters of the lambda, so that it can be called with named
+// arguments when it is bound to a local variable (e.g. `let f = (a b) -> a - b in f(b=1 a=3)`).
+func (l Lambda) parameters() Parameters {
+	params := make(Parameters, len(l.Params))
+	for i, p := range l.Params {
+		params[i] = Parameter{Name: p}
+	}
+	return params
+}
+
+// FunctionalValue returns the lambda as a function that can be called from Go.
+func (l Lambda) FunctionalValue() FunctionalValue {
+	return l.Call
+}
+
+// Equal satisfies the external Equaler interface such as in testify assertions and the cmp package
+func (l Lambda) Equal(other Lambda) bool {
+	return cmp.Equal(l.Params, other.Params) && cmp.Equal(l.Body, other.Body)
+}
+
+func (l Lambda) String() string {
+	body := strings.TrimRight(l.Body.String(), "\n")
+	if len(l.Params) == 1 {
+		return fmt.Sprintf("%s -> %s", l.Params[0], body)
+	}
+	return fmt.Sprintf("(%s) -> %s", strings.Join(l.Params, " "), body)
+}
+
+func (l Lambda) AsString() String {
+	return NewString(l.String())
+}
@@ -0,0 +1,60 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Let is a Tree entry that binds the value of an expression to a local name for the
+// evaluation of its body, such as `let net = :gross: - :tax: in net * 0.2 + net * 0.05`.
+// The value is evaluated once. The name is referred to by its bare name in the body and
+// it is not visible outside of the body.
+type Let struct {
+	Name  string
+	Value Tree
+	Body  Tree
+}
+
+func NewLet(name string, value, body Tree) Let {
+	return Let{
+		Name:  name,
+		Value: value,
+		Body:  body,
+	}
+}
+
+func (l Let) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := l.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the value of the binding and then the body, in which the binding is visible.
+func (l Let) Eval(opts ...treeOption) Value {
+	cfg := &treeConfig{}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	v := l.Value.Eval(opts...)
+	if u, ok := v.(Undefined); ok {
+		return u
+	}
+
+	return l.Body.Eval(append(cfg.options(), withScope(cfg.scope.push(Variables{l.Name: v})))...)
+}
+
+func (l Let) String() string {
+	return fmt.Sprintf("let %s = %s in %s", l.Name, strings.TrimRight(l.Value.String(), "\n"), strings.TrimRight(l.Body.String(), "\n"))
+}
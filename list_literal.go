@@ -0,0 +1,63 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// ListLiteral is a Tree entry that holds a list expression such as `[1 2 "x" :v:]`.
+// Its elements are evaluated at evaluation time, into a MultiValue.
+type ListLiteral struct {
+	Elements []Tree
+}
+
+func NewListLiteral(elements ...Tree) ListLiteral {
+	if len(elements) == 0 {
+		// the empty list
+		return ListLiteral{}
+	}
+
+	return ListLiteral{
+		Elements: elements,
+	}
+}
+
+func (l ListLiteral) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := l.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the elements of the list and returns them as a MultiValue.
+func (l ListLiteral) Eval(opts ...treeOption) Value {
+	values := make([]Value, 0, len(l.Elements))
+
+	for _, e := range l.Elements {
+		v := e.Eval(opts...)
+		if u, ok := v.(Undefined); ok {
+			return u
+		}
+		values = append(values, v)
+	}
+
+	return NewMultiValue(values...)
+}
+
+func (l ListLiteral) String() string {
+	elems := lo.Map(l.Elements, func(item Tree, index int) string {
+		return strings.TrimRight(item.String(), "\n")
+	})
+	return fmt.Sprintf("[%s]", strings.Join(elems, ", "))
+}
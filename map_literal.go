@@ -0,0 +1,69 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// MapLiteral is a Tree entry that holds a map expression such as `{"a": 1, "b": :x:}`.
+// Its values are evaluated at evaluation time, into a Map.
+type MapLiteral struct {
+	Entries []MapLiteralEntry
+}
+
+// MapLiteralEntry is a key / value pair of a MapLiteral.
+type MapLiteralEntry struct {
+	Key   string
+	Value Tree
+}
+
+func NewMapLiteral(entries ...MapLiteralEntry) MapLiteral {
+	if len(entries) == 0 {
+		// the empty map
+		return MapLiteral{}
+	}
+
+	return MapLiteral{
+		Entries: entries,
+	}
+}
+
+func (m MapLiteral) Calculate(val entry, op Operator, cfg *treeConfig) entry {
+	rhsVal := m.Eval(cfg.options()...)
+	if u, ok := rhsVal.(Undefined); ok {
+		return u
+	}
+
+	if val == nil {
+		return rhsVal
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	val = calculate(val.(Value), op, rhsVal)
+
+	return val
+}
+
+// Eval evaluates the values of the map and returns them as a Map.
+func (m MapLiteral) Eval(opts ...treeOption) Value {
+	entries := make([]MapEntry, 0, len(m.Entries))
+
+	for _, e := range m.Entries {
+		v := e.Value.Eval(opts...)
+		if u, ok := v.(Undefined); ok {
+			return u
+		}
+		entries = append(entries, MapEntry{Key: e.Key, Value: v})
+	}
+
+	return NewMap(entries...)
+}
+
+func (m MapLiteral) String() string {
+	entries := lo.Map(m.Entries, func(item MapLiteralEntry, index int) string {
+		return fmt.Sprintf("%q: %s", item.Key, strings.TrimRight(item.Value.String(), "\n"))
+	})
+	return fmt.Sprintf("{%s}", strings.Join(entries, ", "))
+}
@@ -3,6 +3,7 @@ package gal
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -25,6 +26,10 @@ func (df DotFunction) Calculate(val entry, cfg *treeConfig) entry {
 		return NewUndefinedWithReasonf("syntax error: DotFunction called on non-object: [object: '%T'] [member: '%s'] (check if the receiver is nil)", val, df.Name)
 	}
 
+	if isNilObject(receiver) {
+		return NewUndefinedWithReasonf("error: DotFunction called on a nil object: [member: '%s'] (use '?.%s' if the object is optional)", df.Name, df.Name)
+	}
+
 	// if the object is a ObjectValue, we need to get the underlying object
 	// ObjectValue is a wrapper for "general" objects (i.e. non-gal.Value objects)
 	// By Object, we mean a Go struct, a pointer to a struct or a Go interface.
@@ -37,7 +42,8 @@ func (df DotFunction) Calculate(val entry, cfg *treeConfig) entry {
 	vFv, ok := ObjectGetMethod(receiver, df.Name)
 	if ok {
 		df.BodyFn = vFv
-		rhsVal := df.Eval(WithFunctions(cfg.functions), WithVariables(cfg.variables), WithObjects(cfg.objects))
+		df.Params = objectParameters(receiver, df.Name)
+		rhsVal := df.Eval(cfg.options()...)
 		if u, ok := rhsVal.(Undefined); ok {
 			return u
 		}
@@ -59,6 +65,10 @@ func (dv DotVariable) Calculate(val entry) entry {
 		return NewUndefinedWithReasonf("syntax error: DotVariable called on non-object: [object: '%T'] [member: '%s'] (check if the receiver is nil)", fmt.Sprintf("%T", val), dv.Name)
 	}
 
+	if isNilObject(receiver) {
+		return NewUndefinedWithReasonf("error: DotVariable called on a nil object: [member: '%s'] (use '?.%s' if the object is optional)", dv.Name, dv.Name)
+	}
+
 	// if the object is a ObjectValue, we need to get the underlying object
 	// ObjectValue is a wrapper for "general" objects (i.e. non-gal.Value objects)
 	// By Object, we mean a Go struct, a pointer to a struct or a Go interface.
@@ -73,6 +83,44 @@ func (dv DotVariable) Calculate(val entry) entry {
 	return rhsVal
 }
 
+// OptionalDotFunction is a DotFunction used with the optional chaining accessor `?.`
+// (e.g. `aCar.Stereo?.Volume()`).
+// When its receiver is Null or a nil object, the method is not called: the accessor yields
+// Null and the rest of the chain of accessors is skipped (see Tree.Calc).
+type OptionalDotFunction struct{ DotFunction }
+
+func (odf OptionalDotFunction) String() string {
+	return "?." + odf.DotFunction.String()
+}
+
+// OptionalDotVariable is a DotVariable used with the optional chaining accessor `?.`
+// (e.g. `aCar.Stereo?.Brand`).
+// When its receiver is Null or a nil object, the accessor yields Null and the rest of the
+// chain of accessors is skipped (see Tree.Calc).
+type OptionalDotVariable struct{ DotVariable }
+
+func (odv OptionalDotVariable) String() string {
+	return "?." + odv.DotVariable.String()
+}
+
+// isNilObject returns true when val is Null or an ObjectValue that holds a nil object.
+func isNilObject(val entry) bool {
+	switch typedVal := val.(type) {
+	case Null:
+		return true
+	case ObjectValue:
+		return typedVal.Object == nil || isNilPointer(reflect.ValueOf(typedVal.Object))
+	default:
+		return false
+	}
+}
+
+// isNilPointer returns true when v is a nil pointer or a nil interface, such as an optional
+// `*struct` field that is not set.
+func isNilPointer(v reflect.Value) bool {
+	return (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()
+}
+
 // Object holds objects that carry properties and methods:
 //   - user-defined objects that may be referenced within a gal expression during evaluation.
 //   - general purpose Go types that have properties and methods.
@@ -120,30 +168,40 @@ func ObjectGetProperty(obj Object, name string) Value {
 		}
 	}
 
+	if m, ok := obj.(Map); ok {
+		return m.Get(name)
+	}
+
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+		// the keys of maps are accessed as properties: only the element is converted
+		elem := v.MapIndex(reflect.ValueOf(name).Convert(t.Key()))
+		if !elem.IsValid() {
+			return NewUndefinedWithReasonf("key '%s' does not exist in map", name)
+		}
+		return reflectValueToGalType(elem)
+	}
+
 	// TODO: we only support `struct` receivers for now. Perhaps simple types (int, float, etc) are worthwhile an enhancement?
 	if t.Kind() != reflect.Struct {
 		return NewUndefinedWithReasonf("object is '%s' but only 'struct' and '*struct' are currently supported", t.Kind())
 	}
 
-	fieldReflectValue := v.FieldByName(name)
+	fieldReflectValue := fieldByName(v, name)
 	if !fieldReflectValue.IsValid() {
 		return NewUndefinedWithReasonf("property '%T:%s' does not exist on object", obj, name)
 	}
 
+	if isNilPointer(fieldReflectValue) {
+		// an optional field that is not set
+		return NewNull()
+	}
+
 	galValue, err := goAnyToGalType(fieldReflectValue.Interface())
 	if err != nil {
 		// allow support for other types to be accessed by Method or Property via
-		//  an object accessor (i.e. DotVariable or DotFunction).
-		t := fieldReflectValue.Type()
-		switch t.Kind() {
-		case reflect.Interface:
-			if t.NumMethod() > 0 {
-				// allow support for (non-empty) interfaces
-				return ObjectValue{Object: fieldReflectValue.Interface()}
-			}
-		case reflect.Struct: // TODO: (!!) incomplete code: see ObjectGetProperty to handle `*struct` scenario.
-			// allow support for struct types
-			return ObjectValue{Object: fieldReflectValue.Interface()}
+		//  an object accessor (i.e. DotVariable or DotFunction) or an IndexAccessor.
+		if objVal, ok := toObjectValue(fieldReflectValue); ok {
+			return objVal
 		}
 
 		return NewUndefinedWithReasonf("object::%T:%s - %s", obj, name, err.Error())
@@ -167,7 +225,7 @@ func ObjectGetMethod(obj Object, name string) (FunctionalValue, bool) {
 		}, false
 	}
 
-	methodReflectValue := value.MethodByName(name)
+	methodReflectValue := methodByName(value, name)
 	if !methodReflectValue.IsValid() {
 		return func(...Value) Value {
 			return NewUndefinedWithReasonf("error: object type '%T' does not have a method '%s' (check if it has a pointer receiver)", obj, name)
@@ -233,20 +291,16 @@ func ObjectGetMethod(obj Object, name string) (FunctionalValue, bool) {
 			return NewUndefinedWithReasonf("invalid function call - object::%T:%s - must return 1 value, returned %d instead", obj, name, len(out))
 		}
 
+		if isNilPointer(out[0]) {
+			return NewNull()
+		}
+
 		retValue, err := goAnyToGalType(out[0].Interface())
 		if err != nil {
 			// allow support for other types to be accessed by Method or Property via
-			//  an objectAccessorEntryKind (i.e. DotVariable or DotFunction).
-			t := out[0].Type()
-			switch t.Kind() {
-			case reflect.Interface:
-				if t.NumMethod() > 0 {
-					// allow support for (non-empty) interfaces
-					return ObjectValue{Object: out[0].Interface()}
-				}
-			case reflect.Struct: // TODO: (!!) incomplete code: see ObjectGetProperty to handle `*struct` scenario.
-				// allow support for struct types
-				return ObjectValue{Object: out[0].Interface()}
+			//  an objectAccessorEntryKind (i.e. DotVariable or DotFunction) or an IndexAccessor.
+			if objVal, ok := toObjectValue(out[0]); ok {
+				return objVal
 			}
 
 			return NewUndefinedWithReasonf("object::%T:%s - %s", obj, name, err.Error())
@@ -257,11 +311,143 @@ func ObjectGetMethod(obj Object, name string) (FunctionalValue, bool) {
 	return closureFn, true
 }
 
+// toObjectValue wraps the Go types that can be traversed with an object accessor or an
+// IndexAccessor into an ObjectValue.
+func toObjectValue(v reflect.Value) (ObjectValue, bool) {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			// allow support for (non-empty) interfaces
+			return ObjectValue{Object: v.Interface()}, true
+		}
+	case reflect.Struct: // TODO: (!!) incomplete code: see ObjectGetProperty to handle `*struct` scenario.
+		// allow support for struct types
+		return ObjectValue{Object: v.Interface()}, true
+	case reflect.Ptr:
+		if !v.IsNil() && t.Elem().Kind() == reflect.Struct {
+			// allow support for `*struct` types
+			return ObjectValue{Object: v.Interface()}, true
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		// allow support for collections via the IndexAccessor
+		return ObjectValue{Object: v.Interface()}, true
+	}
+
+	return ObjectValue{}, false
+}
+
+// reflectValueToGalType converts an element of a Go collection to a gal.Value.
+func reflectValueToGalType(v reflect.Value) Value {
+	if isNilPointer(v) {
+		// a nil element, such as a nil `*struct` in a slice
+		return NewNull()
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		// the element of a collection of type `any`: use its dynamic type
+		if v.IsNil() {
+			return NewNull()
+		}
+		v = v.Elem()
+	}
+
+	galValue, err := goAnyToGalType(v.Interface())
+	if err != nil {
+		if objVal, ok := toObjectValue(v); ok {
+			return objVal
+		}
+
+		if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			// allow support for `*struct` elements
+			return ObjectValue{Object: v.Interface()}
+		}
+
+		return NewUndefinedWithReasonf("collection element - %s", err.Error())
+	}
+
+	return galValue
+}
+
+// objectToMultiValue converts a Go slice or array, or a pointer to one, to a MultiValue.
+func objectToMultiValue(obj any) (MultiValue, bool) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return MultiValue{}, false
+	}
+
+	values := make([]Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, reflectValueToGalType(v.Index(i)))
+	}
+
+	return NewMultiValue(values...), true
+}
+
+// galValueToReflectValue converts a gal.Value to a Go value of type t, such as a map key.
+//
+//nolint:gosec // ignoring overflow conversion
+func galValueToReflectValue(val Value, t reflect.Type) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.String:
+		if v, ok := val.(Stringer); ok {
+			return reflect.ValueOf(v.AsString().RawString()).Convert(t), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, ok := val.(Numberer); ok {
+			return integerToReflectValue(v.Number(), t)
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, ok := val.(Numberer); ok {
+			return reflect.ValueOf(v.Number().Float64()).Convert(t), nil
+		}
+	case reflect.Bool:
+		if v, ok := val.(Booler); ok {
+			return reflect.ValueOf(v.Bool().value).Convert(t), nil
+		}
+	}
+
+	return reflect.Value{}, errors.Errorf("value '%s' cannot be converted to Go type '%s'", val.String(), t.String())
+}
+
+// integerToReflectValue converts n to a Go integer of type t.
+// Unlike a conversion, it rejects the numbers that are not integers or that overflow t.
+func integerToReflectValue(n Number, t reflect.Type) (reflect.Value, error) {
+	if !n.value.IsInteger() {
+		return reflect.Value{}, errors.Errorf("value '%s' is not an integer and cannot be converted to Go type '%s'", n.String(), t.String())
+	}
+
+	rv := reflect.New(t).Elem()
+	i := n.value.BigInt()
+
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !i.IsUint64() || rv.OverflowUint(i.Uint64()) {
+			return reflect.Value{}, errors.Errorf("value '%s' overflows Go type '%s'", n.String(), t.String())
+		}
+		rv.SetUint(i.Uint64())
+	default:
+		if !i.IsInt64() || rv.OverflowInt(i.Int64()) {
+			return reflect.Value{}, errors.Errorf("value '%s' overflows Go type '%s'", n.String(), t.String())
+		}
+		rv.SetInt(i.Int64())
+	}
+
+	return rv, nil
+}
+
 // attempt to convert a Go 'any' type to an equivalent gal.Value
 //
 //nolint:gosec // ignoring overflow conversion
 func goAnyToGalType(value any) (Value, error) {
 	switch typedValue := value.(type) {
+	case nil:
+		return NewNull(), nil
 	case Value:
 		return typedValue, nil
 	case int:
@@ -288,7 +474,81 @@ func goAnyToGalType(value any) (Value, error) {
 		return NewString(typedValue), nil
 	case bool:
 		return NewBool(typedValue), nil
+	case map[string]any:
+		return NewMapFromGo(typedValue)
+	case []any:
+		values := make([]Value, 0, len(typedValue))
+		for _, e := range typedValue {
+			v, err := goAnyToGalType(e)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return NewMultiValue(values...), nil
 	default:
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+			return goMapToGalMap(v)
+		}
 		return nil, errors.Errorf("type '%T' cannot be mapped to gal.Value", typedValue)
 	}
 }
+
+// goMapToGalMap converts a Go map with string keys to a Map.
+// The values that cannot be mapped to a gal.Value are held in an ObjectValue, when possible.
+func goMapToGalMap(v reflect.Value) (Map, error) {
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	entries := make([]MapEntry, 0, len(keys))
+
+	for _, k := range keys {
+		elem := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))
+
+		galValue, err := goAnyToGalType(elem.Interface())
+		if err != nil {
+			objVal, ok := toObjectValue(elem)
+			if !ok {
+				return Map{}, errors.Errorf("map key '%s': %s", k, err.Error())
+			}
+			galValue = objVal
+		}
+
+		entries = append(entries, MapEntry{Key: k, Value: galValue})
+	}
+
+	return NewMap(entries...), nil
+}
+
+// attempt to convert a gal.Value to an equivalent Go type.
+// Values that have no Go equivalent are returned as they are.
+func galTypeToGoAny(value Value) any {
+	switch typedValue := value.(type) {
+	case Number:
+		if typedValue.value.IsInteger() {
+			return typedValue.Int64()
+		}
+		return typedValue.Float64()
+	case String:
+		return typedValue.RawString()
+	case Bool:
+		return typedValue.value
+	case Map:
+		return typedValue.ToGoMap()
+	case MultiValue:
+		values := make([]any, 0, typedValue.Size())
+		for _, v := range typedValue.values {
+			values = append(values, galTypeToGoAny(v))
+		}
+		return values
+	case ObjectValue:
+		return typedValue.Object
+	case Null:
+		return nil
+	default:
+		return value
+	}
+}
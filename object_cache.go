@@ -0,0 +1,64 @@
+package gal
+
+import (
+	"reflect"
+	"sync"
+)
+
+// memberKey identifies a member of a Go type by name.
+type memberKey struct {
+	t    reflect.Type
+	name string
+}
+
+// fieldIndices and methodIndices cache the look up of the members of objects by name.
+// They spare walking the fields and the methods of Go types with reflection each time a
+// property or a method of an object is accessed (e.g. when an expression is evaluated
+// repeatedly).
+// NOTE: only the members that exist are cached: the caches are bounded by the Go types in use.
+var (
+	fieldIndices  sync.Map // memberKey -> []int
+	methodIndices sync.Map // memberKey -> int
+)
+
+// fieldByName returns the field of the struct held in v by name.
+// The returned reflect.Value is not valid when there is no such field.
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	key := memberKey{t: v.Type(), name: name}
+
+	index, ok := fieldIndices.Load(key)
+	if !ok {
+		sf, found := key.t.FieldByName(name)
+		if !found {
+			return reflect.Value{}
+		}
+		index, _ = fieldIndices.LoadOrStore(key, sf.Index)
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	field, err := v.FieldByIndexErr(index.([]int))
+	if err != nil {
+		// the field is promoted from a nil embedded struct pointer
+		return reflect.Value{}
+	}
+
+	return field
+}
+
+// methodByName returns the method of v by name.
+// The returned reflect.Value is not valid when there is no such method.
+func methodByName(v reflect.Value, name string) reflect.Value {
+	key := memberKey{t: v.Type(), name: name}
+
+	index, ok := methodIndices.Load(key)
+	if !ok {
+		m, found := key.t.MethodByName(name)
+		if !found {
+			return reflect.Value{}
+		}
+		index, _ = methodIndices.LoadOrStore(key, m.Index)
+	}
+
+	//nolint:errcheck // life's too short to check for type assertion success here
+	return v.Method(index.(int))
+}
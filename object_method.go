@@ -24,11 +24,12 @@ func NewObjectMethod(objectName, propertyName string, args ...Tree) ObjectMethod
 //nolint:errcheck // life's too short to check for type assertion success here
 func (om ObjectMethod) Calculate(val entry, op Operator, cfg *treeConfig) entry {
 	// attempt to get body of a user-provided object's method.
-	bodyFn := cfg.ObjectMethod(om)
+	bodyFn, params := cfg.ObjectMethod(om)
 
 	fn := NewFunction(om.MethodName, bodyFn, om.Args...)
+	fn.Params = params
 
-	rhsVal := fn.Eval(WithFunctions(cfg.functions), WithVariables(cfg.variables), WithObjects(cfg.objects))
+	rhsVal := fn.Eval(cfg.options()...)
 	if u, ok := rhsVal.(Undefined); ok {
 		return u
 	}
@@ -37,5 +37,8 @@ func (o ObjectProperty) Calculate(val entry, op Operator, cfg *treeConfig) entry
 }
 
 func (o ObjectProperty) String() string {
+	if o.PropertyName == "" {
+		return o.ObjectName
+	}
 	return fmt.Sprintf("%s.%s", o.ObjectName, o.PropertyName)
 }
@@ -237,3 +237,50 @@ func TestValueAsObject(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, gal.NewNumber(323, 0), val(gal.NewNumber(200, 0)))
 }
+
+type Order struct {
+	ID       string
+	Customer *Customer
+	Items    []Item
+	ItemPtrs []*Item
+}
+
+type Item struct {
+	Name  string
+	Total int
+}
+
+type Customer struct {
+	Name    string
+	Address *Address
+	loyalty *Loyalty
+}
+
+func (c *Customer) Loyalty() *Loyalty {
+	return c.loyalty
+}
+
+// Discount returns the discount on amount, at rate.
+func (c *Customer) Discount(amount, rate gal.Number) gal.Value {
+	return amount.Multiply(rate)
+}
+
+// MethodParameters implements gal.ParameterDeclarer.
+func (c *Customer) MethodParameters(name string) gal.Parameters {
+	if name == "Discount" {
+		return gal.Parameters{{Name: "amount"}, {Name: "rate", Default: gal.NewNumberFromFloat(0.1)}}
+	}
+	return nil
+}
+
+type Address struct {
+	City string
+}
+
+type Loyalty struct {
+	tier string
+}
+
+func (l *Loyalty) Tier() string {
+	return l.tier
+}
@@ -13,27 +13,47 @@ const (
 	Multiply           Operator = "*"
 	Divide             Operator = "/"
 	Modulus            Operator = "%"
+	FloorDivide        Operator = "~/"
+	FloorModulus       Operator = "%%"
 	Power              Operator = "**"
 	LShift             Operator = "<<"
 	RShift             Operator = ">>"
+	BitwiseAnd         Operator = "&"
+	BitwiseOr          Operator = "|"
+	BitwiseXor         Operator = "^"
+	BitwiseNot         Operator = "~"
 	LessThan           Operator = "<"
 	LessThanOrEqual    Operator = "<="
 	EqualTo            Operator = "=="
 	NotEqualTo         Operator = "!="
 	GreaterThan        Operator = ">"
 	GreaterThanOrEqual Operator = ">="
+	In                 Operator = "in"
+	NotIn              Operator = "not in"
+	Match              Operator = "=~"
+	NotMatch           Operator = "!~"
+	NullCoalesce       Operator = "??"
 	And                Operator = "And" // NOTE: case sentive for now
 	And2               Operator = "&&"
 	Or                 Operator = "Or" // NOTE: case sentive for now
 	Or2                Operator = "||"
+	Not                Operator = "Not" // NOTE: case sentive for now
+	Not2               Operator = "!"
 )
 
+// unaryOperators are the operators that may be used as a prefix to an operand.
+// Note that Plus and Minus are also binary operators.
+func unaryOperators(o Operator) bool {
+	return o == Plus || o == Minus || o == Not || o == Not2 || o == BitwiseNot
+}
+
 func powerOperators(o Operator) bool {
 	return o == Power
 }
 
 func multiplicativeOperators(o Operator) bool {
-	return o == Multiply || o == Divide || o == Modulus
+	return o == Multiply || o == Divide || o == Modulus ||
+		o == FloorDivide || o == FloorModulus
 }
 
 func additiveOperators(o Operator) bool {
@@ -44,10 +64,33 @@ func bitwiseShiftOperators(o Operator) bool {
 	return o == LShift || o == RShift
 }
 
+// NOTE: as in Python, the bitwise operators have a lower precedence than the shift operators
+// and a higher precedence than the comparative operators: '&' first, then '^' and finally '|'.
+func bitwiseAndOperators(o Operator) bool {
+	return o == BitwiseAnd
+}
+
+func bitwiseXorOperators(o Operator) bool {
+	return o == BitwiseXor
+}
+
+func bitwiseOrOperators(o Operator) bool {
+	return o == BitwiseOr
+}
+
+// NOTE: as in Swift, the null-coalescing operator has a lower precedence than the arithmetic
+// and bitwise operators and a higher precedence than the comparative operators:
+// `:a: ?? 0 + 1 > 5` is `(:a: ?? (0 + 1)) > 5`.
+func nullCoalescingOperators(o Operator) bool {
+	return o == NullCoalesce
+}
+
 func comparativeOperators(o Operator) bool {
 	return o == GreaterThan || o == GreaterThanOrEqual ||
 		o == LessThan || o == LessThanOrEqual ||
-		o == EqualTo || o == NotEqualTo
+		o == EqualTo || o == NotEqualTo ||
+		o == In || o == NotIn ||
+		o == Match || o == NotMatch
 }
 
 func logicalOperators(o Operator) bool {
@@ -0,0 +1,144 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Parameter declares a parameter of a function: its name, by which an argument can be passed
+// (e.g. `round(:x: places=2)`), and its default value, if any.
+// A Parameter which Default is nil is required.
+type Parameter struct {
+	Name    string
+	Default Value
+}
+
+// Parameters declares the parameters of a function, in order.
+// The arguments of a call are bound to the parameters before the body of the function runs:
+// the positional arguments first, then the named arguments and finally the default values
+// of the parameters that remain unbound.
+type Parameters []Parameter
+
+// FunctionParameters is a collection of Parameters in the form of a map which keys are the
+// names of user-defined functions. See WithFunctionParameters.
+type FunctionParameters map[string]Parameters
+
+func (fp FunctionParameters) Get(name string) (Parameters, bool) {
+	if fp == nil {
+		return nil, false
+	}
+	params, ok := fp[name]
+	return params, ok
+}
+
+// ParameterDeclarer is implemented by the user-defined objects that declare the parameters of
+// their methods, so that the methods can be called with named arguments and default values.
+// It returns nil for a method that does not declare its parameters.
+type ParameterDeclarer interface {
+	MethodParameters(name string) Parameters
+}
+
+// objectParameters returns the parameters that obj declares for its method name, if any.
+func objectParameters(obj Object, name string) Parameters {
+	if pd, ok := obj.(ParameterDeclarer); ok {
+		return pd.MethodParameters(name)
+	}
+	return nil
+}
+
+// NamedArgument is a Tree entry that holds an argument of a function call that is passed by
+// the name of the parameter it is bound to, such as `places=2` in `round(:x: places=2)`.
+type NamedArgument struct {
+	Name  string
+	Value Tree
+}
+
+func NewNamedArgument(name string, value Tree) NamedArgument {
+	return NamedArgument{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// Equal satisfies the external Equaler interface such as in testify assertions and the cmp package
+func (na NamedArgument) Equal(other NamedArgument) bool {
+	return na.Name == other.Name &&
+		cmp.Equal(na.Value, other.Value)
+}
+
+func (na NamedArgument) String() string {
+	return fmt.Sprintf("%s=%s", na.Name, strings.TrimRight(na.Value.String(), "\n"))
+}
+
+// namedArgument returns the NamedArgument held in arg, an argument of a function call.
+func namedArgument(arg Tree) (NamedArgument, bool) {
+	if len(arg) != 1 {
+		return NamedArgument{}, false
+	}
+	na, ok := arg[0].(NamedArgument)
+	return na, ok
+}
+
+// namedValue is the Value of a NamedArgument.
+type namedValue struct {
+	name  string
+	value Value
+}
+
+// bind binds the arguments of a call to the function fname to the parameters.
+// It returns the arguments of the body of the function, in the order of the parameters.
+//
+// The positional arguments that exceed the parameters are passed as they are: the body of the
+// function checks its number of arguments. Likewise, without named arguments, the required
+// parameters that are not bound are left for the body of the function to report.
+func (params Parameters) bind(fname string, args []Value, named []namedValue) ([]Value, Value) {
+	if len(named) == 0 && len(args) >= len(params) {
+		return args, nil
+	}
+
+	if len(named) > 0 && len(params) == 0 {
+		return nil, NewUndefinedWithReasonf("%s(): named argument '%s': the function does not declare its parameters", fname, named[0].name)
+	}
+
+	bound := make([]Value, len(params))
+	copy(bound, args)
+
+	for _, nv := range named {
+		i := params.index(nv.name)
+		if i < 0 {
+			return nil, NewUndefinedWithReasonf("%s(): unknown argument '%s'", fname, nv.name)
+		}
+		if bound[i] != nil {
+			return nil, NewUndefinedWithReasonf("%s(): duplicate argument '%s'", fname, nv.name)
+		}
+		bound[i] = nv.value
+	}
+
+	for i, p := range params {
+		if bound[i] != nil {
+			continue
+		}
+		if p.Default != nil {
+			bound[i] = p.Default
+			continue
+		}
+		if len(named) > 0 {
+			return nil, NewUndefinedWithReasonf("%s(): missing argument '%s'", fname, p.Name)
+		}
+		// the body of the function reports the missing arguments
+		return bound[:i], nil
+	}
+
+	return append(bound, args[min(len(args), len(bound)):]...), nil
+}
+
+func (params Parameters) index(name string) int {
+	for i, p := range params {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
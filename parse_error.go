@@ -0,0 +1,181 @@
+package gal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseErrorKind classifies the syntax errors reported by the TreeBuilder.
+type ParseErrorKind int
+
+const (
+	InvalidSyntax ParseErrorKind = iota
+	UnexpectedCharacter
+	UnknownOperator
+	UnterminatedString
+	UnterminatedComment
+	InvalidEscapeSequence
+	InvalidVariable
+	InvalidNumber
+	MissingParenthesis
+	MissingBracket
+	MissingBrace
+	InvalidObjectAccessor
+	InternalError
+)
+
+func (k ParseErrorKind) String() string {
+	switch k {
+	case InvalidSyntax:
+		return "invalid syntax"
+	case UnexpectedCharacter:
+		return "unexpected character"
+	case UnknownOperator:
+		return "unknown operator"
+	case UnterminatedString:
+		return "unterminated string"
+	case UnterminatedComment:
+		return "unterminated comment"
+	case InvalidEscapeSequence:
+		return "invalid escape sequence"
+	case InvalidVariable:
+		return "invalid variable"
+	case InvalidNumber:
+		return "invalid number"
+	case MissingParenthesis:
+		return "missing parenthesis"
+	case MissingBracket:
+		return "missing bracket"
+	case MissingBrace:
+		return "missing brace"
+	case InvalidObjectAccessor:
+		return "invalid object accessor"
+	case InternalError:
+		return "internal error"
+	default:
+		return fmt.Sprintf("ParseErrorKind(%d)", int(k))
+	}
+}
+
+// ParseError is a syntax error found by the TreeBuilder while parsing an expression.
+//
+// Offset is the position of the problem in bytes from the start of the expression.
+// Line and Column are 1-based and Column counts characters (runes), not bytes.
+// Token holds the portion of the expression that was being read when the error
+// occurred.
+type ParseError struct {
+	Kind   ParseErrorKind
+	Offset int
+	Line   int
+	Column int
+	Token  string
+	msg    string
+}
+
+func newParseError(kind ParseErrorKind, offset int, token string, format string, a ...any) *ParseError {
+	return &ParseError{
+		Kind:   kind,
+		Offset: offset,
+		Token:  token,
+		msg:    fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		// the position has not been resolved against the full expression yet.
+		return e.msg
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", e.msg, e.Line, e.Column)
+}
+
+// Message returns the description of the error, without its position.
+func (e *ParseError) Message() string {
+	return e.msg
+}
+
+// locate resolves Line and Column from Offset against the full expression.
+func (e *ParseError) locate(expr string) {
+	if e.Offset > len(expr) {
+		e.Offset = len(expr)
+	}
+
+	e.Line, e.Column = position(expr, e.Offset)
+}
+
+// position returns the 1-based line and column of offset in expr.
+func position(expr string, offset int) (int, int) {
+	line, column := 1, 1
+
+	for _, r := range expr[:offset] {
+		if r == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+
+	return line, column
+}
+
+// ParseErrors holds all the syntax errors found in an expression, in the order they
+// appear in the expression.
+// It is returned by a TreeBuilder created WithErrorRecovery.
+type ParseErrors []*ParseError
+
+func (pes ParseErrors) Error() string {
+	msgs := make([]string, 0, len(pes))
+	for _, pe := range pes {
+		msgs = append(msgs, pe.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap allows errors.Is and errors.As to inspect each of the syntax errors.
+func (pes ParseErrors) Unwrap() []error {
+	errs := make([]error, 0, len(pes))
+	for _, pe := range pes {
+		errs = append(errs, pe)
+	}
+	return errs
+}
+
+// appendParseErrors adds err to pes.
+// err may be a *ParseError or a ParseErrors.
+func appendParseErrors(pes ParseErrors, err error) ParseErrors {
+	var nested ParseErrors
+	if errors.As(err, &nested) {
+		return append(pes, nested...)
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return append(pes, pe)
+	}
+
+	return append(pes, newParseError(InternalError, 0, "", "internal error: %s", err.Error()))
+}
+
+// shiftParseError moves the offset of a *ParseError by delta bytes.
+// This is used when an error is reported on a sub-expression to re-base it relative
+// to the enclosing expression.
+// The offsets of all the errors held in a ParseErrors are moved.
+// Errors that are not *ParseError are returned unchanged.
+func shiftParseError(err error, delta int) error {
+	var pes ParseErrors
+	if errors.As(err, &pes) {
+		for _, pe := range pes {
+			pe.Offset += delta
+		}
+		return err
+	}
+
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.Offset += delta
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package gal
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+// Regexp is a compiled regular expression, in Go's RE2 syntax.
+// The TreeBuilder compiles the string literals on the right hand side of the `=~` and `!~`
+// operators into a Regexp, once, when the expression is parsed.
+type Regexp struct {
+	Undefined
+	re *regexp.Regexp
+}
+
+func NewRegexp(pattern string) (Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Regexp{}, err
+	}
+
+	return Regexp{re: re}, nil
+}
+
+// Equal satisfies the external Equaler interface such as in testify assertions and the cmp package
+func (r Regexp) Equal(other Regexp) bool {
+	if r.re == nil || other.re == nil {
+		// the zero Regexp
+		return r.re == nil && other.re == nil
+	}
+	return r.re.String() == other.re.String()
+}
+
+func (r Regexp) String() string {
+	return NewString(r.re.String()).String()
+}
+
+func (r Regexp) AsString() String {
+	return NewString(r.re.String())
+}
+
+// regexpFromValue returns the compiled regular expression held in pattern: either a Regexp or
+// a String, which is compiled through the regexpCache.
+func regexpFromValue(pattern Value) (*regexp.Regexp, Value) {
+	switch p := pattern.(type) {
+	case Regexp:
+		return p.re, nil
+
+	case String:
+		re, err := regexpCache.compile(p.value)
+		if err != nil {
+			return nil, NewUndefinedWithReasonf("invalid regular expression %s: %s", p.String(), err.Error())
+		}
+		return re, nil
+
+	default:
+		return nil, NewUndefinedWithReasonf("regular expression must be a String, got '%s'", pattern.String())
+	}
+}
+
+// matchRegexp returns whether val matches pattern.
+func matchRegexp(val, pattern Value) Value {
+	re, u := regexpFromValue(pattern)
+	if u != nil {
+		return u
+	}
+
+	s, ok := val.(Stringer)
+	if !ok {
+		return NewUndefinedWithReasonf("regular expression match: value '%s' is not a String", val.String())
+	}
+
+	return NewBool(re.MatchString(s.AsString().value))
+}
+
+// regexpCache holds the regular expressions that are compiled at evaluation time (i.e. the
+// patterns that are not string literals, such as `:code: =~ :pattern:`).
+var regexpCache = newRegexpLRU(256)
+
+// regexpLRU is a bounded cache of compiled regular expressions: once full, the least
+// recently used regular expression is evicted.
+type regexpLRU struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+type regexpLRUEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newRegexpLRU(capacity int) *regexpLRU {
+	return &regexpLRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *regexpLRU) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*regexpLRUEntry).re, nil //nolint:errcheck,forcetypeassert // only regexpLRUEntry's are stored
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[pattern] = c.order.PushFront(&regexpLRUEntry{pattern: pattern, re: re})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*regexpLRUEntry).pattern) //nolint:errcheck,forcetypeassert // only regexpLRUEntry's are stored
+	}
+
+	return re, nil
+}
+
+func (c *regexpLRU) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -37,23 +37,223 @@ func (tree Tree) Eval(opts ...treeOption) Value {
 		o(cfg)
 	}
 
+	workingTree := tree.CleanUp()
+
+	// Logical operators have the lowest precedence: they are calculated last, from left to right.
+	// They are also calculated lazily: the right hand side of an And / Or is only evaluated when
+	// the left hand side does not already decide the outcome (i.e. short-circuit evaluation).
+	operands, operators := workingTree.splitAt(logicalOperators)
+
+	if len(operators) > 0 && len(operands[0]) == 0 {
+		return NewUndefinedWithReasonf("syntax error: missing left hand side value for operator '%s'", operators[0].String())
+	}
+
+	val := operands[0].calc(cfg)
+
+	for i, op := range operators {
+		if u, ok := val.(Undefined); ok {
+			return u
+		}
+
+		if len(operands[i+1]) == 0 {
+			return NewUndefinedWithReasonf("syntax error: missing right hand side value for operator '%s'", op.String())
+		}
+
+		if outcome, ok := shortCircuit(val, op); ok {
+			val = outcome
+			continue
+		}
+
+		rhsVal := operands[i+1].calc(cfg)
+		if u, ok := rhsVal.(Undefined); ok {
+			return u
+		}
+
+		val = calculate(val, op, rhsVal)
+	}
+
+	return val
+}
+
+// calc calculates the Value of a Tree that holds no logical operator.
+func (tree Tree) calc(cfg *treeConfig) Value {
+	if tree.TrunkLen() >= 2 && (tree[0] == Not || tree[0] == Not2) {
+		// the TreeBuilder groups a unary operator with its operand in a sub-Tree:
+		// the whole of the rest of the tree is the operand.
+		//nolint:errcheck // life's too short to check for type assertion success here
+		return not(tree[0].(Operator), tree[1:].calc(cfg))
+	}
+
+	if tree.TrunkLen() >= 2 && tree[0] == BitwiseNot {
+		return bitwiseNot(tree[1:].calc(cfg))
+	}
+
+	if tree.hasOperator(nullCoalescingOperators) {
+		return tree.calcNullCoalescing(cfg)
+	}
+
 	// Execute calculation by decreasing order of precedence.
 	// It is necessary to proceed by operator precedence in order
 	// to calculate the expression under conventional rules of precedence.
 	workingTree := tree.
-		CleanUp().
 		Calc(powerOperators, cfg).
 		Calc(multiplicativeOperators, cfg).
 		Calc(additiveOperators, cfg).
 		Calc(bitwiseShiftOperators, cfg).
-		Calc(comparativeOperators, cfg).
-		Calc(logicalOperators, cfg)
+		Calc(bitwiseAndOperators, cfg).
+		Calc(bitwiseXorOperators, cfg).
+		Calc(bitwiseOrOperators, cfg).
+		Calc(comparativeOperators, cfg)
+
+	if workingTree.TrunkLen() == 0 {
+		return NewUndefinedWithReasonf("syntax error: empty expression")
+	}
 
 	// TODO: refactor this
 	// perhaps add Tree.Value() which tests that only one entry is left and that it is a Value
 	// (maybe MultiValue can help too?)
-	//nolint:errcheck // life's too short to check for type assertion success here
-	return workingTree[0].(Value)
+	switch typedE := workingTree[0].(type) {
+	case Value:
+		return typedE
+	case Operator:
+		return NewUndefinedWithReasonf("syntax error: missing operand for operator '%s'", typedE.String())
+	default:
+		return NewUndefinedWithReasonf("internal error: unknown entry type: '%T'", typedE)
+	}
+}
+
+// calcNullCoalescing calculates a Tree that holds the `??` operator.
+// Unlike the other operators, `??` does not propagate the Undefined value of an unknown
+// variable held in its left hand side: it replaces it with its right hand side, which
+// is only evaluated in that case or when the left hand side is Null.
+func (tree Tree) calcNullCoalescing(cfg *treeConfig) Value {
+	// `??` has a higher precedence than the comparative operators: calculate the operands
+	// of the comparisons first.
+	operands, operators := tree.splitAt(comparativeOperators)
+
+	workingTree := Tree{}
+	for i, operand := range operands {
+		val := operand.coalesce(cfg)
+		if u, ok := val.(Undefined); ok {
+			return u
+		}
+
+		workingTree = append(workingTree, val)
+		if i < len(operators) {
+			workingTree = append(workingTree, operators[i])
+		}
+	}
+
+	return workingTree.calc(cfg)
+}
+
+// coalesce returns the value of the first operand of the `??` operators of tree that is
+// neither Null nor the Undefined value of an unknown variable, or the value of the last
+// operand.
+func (tree Tree) coalesce(cfg *treeConfig) Value {
+	operands, _ := tree.splitAt(nullCoalescingOperators)
+
+	var val Value
+	for i, operand := range operands {
+		if len(operand) == 0 {
+			if i == 0 {
+				return NewUndefinedWithReasonf("syntax error: missing left hand side value for operator '%s'", NullCoalesce.String())
+			}
+			return NewUndefinedWithReasonf("syntax error: missing right hand side value for operator '%s'", NullCoalesce.String())
+		}
+
+		val = operand.calc(cfg)
+		if !isNullish(val) {
+			return val
+		}
+	}
+
+	return val
+}
+
+// hasOperator returns true when the trunk of tree holds an operator of the precedence group.
+func (tree Tree) hasOperator(isOperatorInPrecedenceGroup func(Operator) bool) bool {
+	for _, e := range tree {
+		if op, ok := e.(Operator); ok && isOperatorInPrecedenceGroup(op) {
+			return true
+		}
+	}
+	return false
+}
+
+// not returns the logical negation of val.
+func not(op Operator, val Value) Value {
+	if u, ok := val.(Undefined); ok {
+		return u
+	}
+
+	if b, ok := val.(Booler); ok {
+		return b.Bool().Not()
+	}
+
+	return NewUndefinedWithReasonf("syntax error: operator '%s' requires a Bool, got '%s'", op.String(), val.String())
+}
+
+// in returns whether the container holds val.
+func in(val, container Value) Value {
+	if c, ok := container.(Container); ok {
+		return c.Contains(val)
+	}
+
+	return NewUndefinedWithReasonf("operator 'in' requires a MultiValue, a String or a Map, got '%s'", container.String())
+}
+
+// bitwiseNot returns the bitwise complement of val.
+func bitwiseNot(val Value) Value {
+	if u, ok := val.(Undefined); ok {
+		return u
+	}
+
+	if n, ok := val.(Number); ok {
+		return n.BitwiseNot()
+	}
+
+	return NewUndefinedWithReasonf("syntax error: operator '%s' requires a Number, got '%s'", BitwiseNot.String(), val.String())
+}
+
+// splitAt divides a Tree trunk at the operators that belong to the precedence group.
+// It returns the operands and the operators that separate them: there is always one more
+// operand than there are operators, although operands may be empty.
+func (tree Tree) splitAt(isOperatorInPrecedenceGroup func(Operator) bool) ([]Tree, []Operator) {
+	var (
+		operands  []Tree
+		operators []Operator
+	)
+
+	partStart := 0
+
+	for i, e := range tree {
+		if op, ok := e.(Operator); ok && isOperatorInPrecedenceGroup(op) {
+			operands = append(operands, tree[partStart:i])
+			operators = append(operators, op)
+			partStart = i + 1
+		}
+	}
+
+	return append(operands, tree[partStart:]), operators
+}
+
+// shortCircuit returns the outcome of a logical operation when it can be decided from
+// its left hand side alone.
+func shortCircuit(lhs Value, op Operator) (Value, bool) {
+	b, ok := lhs.(Bool)
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case (op == And || op == And2) && !b.value:
+		return False, true
+	case (op == Or || op == Or2) && b.value:
+		return True, true
+	default:
+		return nil, false
+	}
 }
 
 // Split divides a Tree trunk at points where two consecutive entries are present without
@@ -71,7 +271,7 @@ func (tree Tree) Split() []Tree {
 		_, ok1 := tree[i].(Operator)
 		_, ok2 := tree[i-1].(Operator)
 
-		if !ok1 && !ok2 {
+		if !ok1 && !ok2 && !isAccessor(tree[i]) {
 			forest = append(forest, tree[partStart:i])
 			partStart = i
 		}
@@ -80,6 +280,25 @@ func (tree Tree) Split() []Tree {
 	return append(forest, tree[partStart:])
 }
 
+// isAccessor returns true when e accesses the value of the entry before it in the Tree.
+func isAccessor(e entry) bool {
+	switch e.(type) {
+	case DotVariable, DotFunction, OptionalDotVariable, OptionalDotFunction, IndexAccessor:
+		return true
+	default:
+		return false
+	}
+}
+
+// accessorsLen returns the number of accessors at the start of tree.
+func accessorsLen(tree Tree) int {
+	n := 0
+	for n < len(tree) && isAccessor(tree[n]) {
+		n++
+	}
+	return n
+}
+
 // Calc is a reduction operation that calculates the Value of sub-expressions contained
 // in this Tree, based on operator precedence.
 // When isOperatorInPrecedenceGroup returns true, the operator is calculated and the resultant
@@ -107,8 +326,16 @@ func (tree Tree) Calc(isOperatorInPrecedenceGroup func(Operator) bool, cfg *tree
 			}
 		}
 
+		if n := accessorsLen(tree[i+1:]); n > 0 && val != nil && !isOperatorEntry(e) && !isAccessor(e) {
+			// the accessors apply to this entry, not to the value calculated so far
+			// (e.g. '2 ** :list:[1]'): calculate them together first.
+			val = tree[i:i+1+n].Calculate(val, op, cfg)
+			i += n
+			continue
+		}
+
 		switch typedE := e.(type) {
-		case Bool, MultiValue, Number, String:
+		case Bool, Map, MultiValue, Null, Number, ObjectValue, Regexp, String:
 			vVal, _ := val.(Value) // avoid panic if val is nil
 			val = valueEntryKindFn(vVal, op, e.(Value))
 
@@ -135,6 +362,27 @@ func (tree Tree) Calc(isOperatorInPrecedenceGroup func(Operator) bool, cfg *tree
 		case ObjectMethod:
 			val = typedE.Calculate(val, op, cfg)
 
+		case Conditional:
+			val = typedE.Calculate(val, op, cfg)
+
+		case ListLiteral:
+			val = typedE.Calculate(val, op, cfg)
+
+		case InterpolatedString:
+			val = typedE.Calculate(val, op, cfg)
+
+		case MapLiteral:
+			val = typedE.Calculate(val, op, cfg)
+
+		case Lambda:
+			val = typedE.Calculate(val, op, cfg)
+
+		case Let:
+			val = typedE.Calculate(val, op, cfg)
+
+		case Block:
+			val = typedE.Calculate(val, op, cfg)
+
 		case Variable:
 			val = typedE.Calculate(val, op, cfg)
 
@@ -147,6 +395,30 @@ func (tree Tree) Calc(isOperatorInPrecedenceGroup func(Operator) bool, cfg *tree
 		case DotVariable:
 			val = typedE.Calculate(val)
 
+		case OptionalDotFunction:
+			if isNilObject(val) {
+				// skip the rest of the chain of accessors
+				val = NewNull()
+				i += accessorsLen(tree[i+1:])
+				continue
+			}
+			val = typedE.Calculate(val, cfg)
+
+		case OptionalDotVariable:
+			if isNilObject(val) {
+				// skip the rest of the chain of accessors
+				val = NewNull()
+				i += accessorsLen(tree[i+1:])
+				continue
+			}
+			val = typedE.Calculate(val)
+
+		case IndexAccessor:
+			val = typedE.Calculate(val, cfg)
+
+		case NamedArgument:
+			return Tree{NewUndefinedWithReasonf("syntax error: named argument '%s' outside of a function call", typedE.Name)}
+
 		case Undefined:
 			return Tree{e}
 
@@ -167,7 +439,7 @@ func (tree Tree) Calculate(val entry, op Operator, cfg *treeConfig) entry {
 		return NewUndefinedWithReasonf("syntax error: missing left hand side value for operator '%s'", op.String())
 	}
 
-	rhsVal := tree.Eval(WithFunctions(cfg.functions), WithVariables(cfg.variables), WithObjects(cfg.objects))
+	rhsVal := tree.Eval(cfg.options()...)
 	if u, ok := rhsVal.(Undefined); ok {
 		return u
 	}
@@ -238,15 +510,37 @@ func (tree Tree) String(indents ...string) string {
 		case Function:
 			res += fmt.Sprintf("%sFunction %s\n", indent, typedE.String())
 		case Variable:
-			res += fmt.Sprintf("%sVariable %s\n", indent, typedE.Name)
+			res += fmt.Sprintf("%sVariable %s\n", indent, typedE.String())
 		case ObjectProperty:
 			res += fmt.Sprintf("%sObjectProperty %s\n", indent, typedE.String())
 		case ObjectMethod:
 			res += fmt.Sprintf("%sObjectMethod %s\n", indent, typedE.String())
+		case Conditional:
+			res += fmt.Sprintf("%sConditional %s\n", indent, typedE.String())
+		case ListLiteral:
+			res += fmt.Sprintf("%sListLiteral %s\n", indent, typedE.String())
+		case InterpolatedString:
+			res += fmt.Sprintf("%sInterpolatedString %s\n", indent, typedE.String())
+		case MapLiteral:
+			res += fmt.Sprintf("%sMapLiteral %s\n", indent, typedE.String())
+		case Let:
+			res += fmt.Sprintf("%sLet %s\n", indent, typedE.String())
+		case Block:
+			res += fmt.Sprintf("%sBlock %s\n", indent, typedE.String())
+		case Assignment:
+			res += fmt.Sprintf("%sAssignment %s\n", indent, typedE.String())
 		case DotFunction:
 			res += fmt.Sprintf("%sDotFunction %s\n", indent, typedE.String())
 		case DotVariable:
 			res += fmt.Sprintf("%sDotVariable %s\n", indent, typedE.String())
+		case OptionalDotFunction:
+			res += fmt.Sprintf("%sOptionalDotFunction %s\n", indent, typedE.String())
+		case OptionalDotVariable:
+			res += fmt.Sprintf("%sOptionalDotVariable %s\n", indent, typedE.String())
+		case IndexAccessor:
+			res += fmt.Sprintf("%sIndexAccessor %s\n", indent, typedE.String())
+		case NamedArgument:
+			res += fmt.Sprintf("%sNamedArgument %s\n", indent, typedE.String())
 		default:
 			res += fmt.Sprintf("%sTODO: unsupported - %T\n", indent, e)
 		}
@@ -256,6 +550,10 @@ func (tree Tree) String(indents ...string) string {
 }
 
 func calculate(lhs Value, op Operator, rhs Value) Value {
+	if outVal, ok := calculateNull(lhs, op, rhs); ok {
+		return outVal
+	}
+
 	var outVal Value
 
 	switch op {
@@ -277,12 +575,27 @@ func calculate(lhs Value, op Operator, rhs Value) Value {
 	case Modulus:
 		outVal = lhs.Mod(rhs)
 
+	case FloorDivide:
+		outVal = lhs.FloorDivide(rhs)
+
+	case FloorModulus:
+		outVal = lhs.FloorMod(rhs)
+
 	case LShift:
 		outVal = lhs.LShift(rhs)
 
 	case RShift:
 		outVal = lhs.RShift(rhs)
 
+	case BitwiseAnd:
+		outVal = lhs.BitwiseAnd(rhs)
+
+	case BitwiseOr:
+		outVal = lhs.BitwiseOr(rhs)
+
+	case BitwiseXor:
+		outVal = lhs.BitwiseXor(rhs)
+
 	case LessThan:
 		outVal = lhs.LessThan(rhs)
 
@@ -301,6 +614,24 @@ func calculate(lhs Value, op Operator, rhs Value) Value {
 	case GreaterThanOrEqual:
 		outVal = lhs.GreaterThanOrEqual(rhs)
 
+	case Match:
+		outVal = matchRegexp(lhs, rhs)
+
+	case NotMatch:
+		outVal = matchRegexp(lhs, rhs)
+		if b, ok := outVal.(Bool); ok {
+			outVal = b.Not()
+		}
+
+	case In:
+		outVal = in(lhs, rhs)
+
+	case NotIn:
+		outVal = in(lhs, rhs)
+		if b, ok := outVal.(Bool); ok {
+			outVal = b.Not()
+		}
+
 	case And, And2:
 		outVal = lhs.And(rhs)
 
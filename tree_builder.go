@@ -1,136 +1,1418 @@
 package gal
 
 import (
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
-type TreeBuilder struct{}
+type TreeBuilder struct {
+	recoverErrors bool
+	argSeparator  ArgumentSeparator
+}
+
+func NewTreeBuilder(opts ...treeBuilderOption) *TreeBuilder {
+	tb := &TreeBuilder{}
+
+	for _, o := range opts {
+		o(tb)
+	}
 
-func NewTreeBuilder() *TreeBuilder {
-	return &TreeBuilder{}
+	return tb
+}
+
+type treeBuilderOption func(*TreeBuilder)
+
+// WithErrorRecovery is a functional parameter for the TreeBuilder.
+// It makes FromExpr carry on parsing past syntax errors so that all of them are reported
+// in one pass, as a ParseErrors.
+// The Tree returned alongside the errors is a best-effort representation of the expression
+// in which the parts that could not be parsed are replaced with an Undefined value.
+func WithErrorRecovery() treeBuilderOption {
+	return func(tb *TreeBuilder) {
+		tb.recoverErrors = true
+	}
 }
 
+// ArgumentSeparator is the separator of the arguments of functions and of the elements of lists.
+type ArgumentSeparator int
+
+const (
+	// SpaceSeparator separates the arguments with blanks: `f(1 2)`.
+	// A '+' or '-' that is preceded by a blank and glued to its operand starts a new argument:
+	// `f(1 -2)` has 2 arguments.
+	// This is the default.
+	SpaceSeparator ArgumentSeparator = iota
+
+	// CommaSeparator separates the arguments with commas: `f(1, 2)`.
+	// Each argument is a single expression: `f(1 -2)` has 1 argument, `1 - 2`.
+	CommaSeparator
+
+	// CommaOrSpaceSeparator accepts both separators: the arguments of a function call (or the
+	// elements of a list) that holds a comma are separated with commas, otherwise with blanks.
+	CommaOrSpaceSeparator
+)
+
+// WithArgumentSeparator is a functional parameter for the TreeBuilder.
+// It sets the separator of the arguments of functions and of the elements of lists.
+func WithArgumentSeparator(sep ArgumentSeparator) treeBuilderOption {
+	return func(tb *TreeBuilder) {
+		tb.argSeparator = sep
+	}
+}
+
+// FromExpr parses expr and returns its Tree representation.
+// Syntax errors are returned as a *ParseError, or as a ParseErrors when the TreeBuilder
+// was created WithErrorRecovery.
 func (tb TreeBuilder) FromExpr(expr string) (Tree, error) {
+	tree, err := tb.fromExpr(expr, parseContext{})
+	if err != nil {
+		var pes ParseErrors
+		if errors.As(err, &pes) {
+			for _, pe := range pes {
+				pe.locate(expr)
+			}
+			return tree, pes
+		}
+
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			pe.locate(expr)
+		}
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// parseContext describes where the sub-expression being parsed sits in the expression.
+type parseContext struct {
+	// argList is set when parsing the arguments of a function: a '+' or '-' that is preceded
+	// by a blank and glued to its operand (e.g. 'f(1 -2)') then starts a new argument.
+	argList bool
+
+	// argument is set when parsing a comma-separated argument of a function: like the
+	// arguments separated with blanks, it is an expression, not a script.
+	argument bool
+
+	// namedArguments is set when parsing the arguments of a function call, which may be
+	// passed by name (e.g. 'round(:x: places=2)').
+	namedArguments bool
+
+	// locals holds the names of the parameters of the lambdas that enclose the sub-expression.
+	// They are referred to by their bare name (e.g. 'x -> x * 2').
+	locals []string
+}
+
+// nested returns the context of a sub-expression enclosed in the sub-expression being parsed.
+func (ctx parseContext) nested(argList bool) parseContext {
+	return parseContext{
+		argList: argList,
+		locals:  ctx.locals,
+	}
+}
+
+// nestedArgument returns the context of a comma-separated argument of a function call.
+func (ctx parseContext) nestedArgument() parseContext {
+	return parseContext{
+		argument:       true,
+		namedArguments: ctx.namedArguments,
+		locals:         ctx.locals,
+	}
+}
+
+// withNamedArguments returns a copy of the context in which the arguments may be named or not.
+func (ctx parseContext) withNamedArguments(named bool) parseContext {
+	ctx.namedArguments = named
+	return ctx
+}
+
+// withLocals returns a copy of the context in which names are also locals.
+func (ctx parseContext) withLocals(names ...string) parseContext {
+	ctx.locals = append(append([]string{}, ctx.locals...), names...)
+	return ctx
+}
+
+func (ctx parseContext) isLocal(name string) bool {
+	return lo.Contains(ctx.locals, name)
+}
+
+// fromExpr parses expr, which may be a sub-expression of the expression being built.
+// The offsets of the errors it returns are relative to the start of expr.
+// When recovering from errors, it returns a best-effort Tree alongside a ParseErrors.
+func (tb TreeBuilder) fromExpr(expr string, ctx parseContext) (Tree, error) {
+	if !ctx.argList && !ctx.argument {
+		if seps := statementSeparators(expr); len(seps) > 0 || isAssignment(expr) {
+			return tb.blockFromExpr(expr, seps, ctx)
+		}
+	}
+
 	tree := Tree{}
 
-	//nolint:errcheck // life's too short to check for type assertion success here
+	var errs ParseErrors
+
+	glued := map[int]bool{} // positions in tree of the '+' and '-' that are glued to their operand
+
 	for idx := 0; idx < len(expr); {
+		if params, l, ok := readLambdaParams(expr[idx:]); ok {
+			// the body of the lambda extends to the end of expr, or to the end of the
+			// argument when parsing the arguments of a function.
+			lambda, rest, err := tb.lambdaFromExpr(expr[idx+l:], params, ctx)
+			if err != nil {
+				err = shiftParseError(err, idx+l)
+				if !tb.recoverErrors {
+					return nil, err
+				}
+				errs = appendParseErrors(errs, err)
+				if lambda == nil {
+					lambda = NewUndefinedWithReasonf("%s", err.Error())
+				}
+			}
+			tree = append(tree, lambda)
+			tree = append(tree, rest...)
+			break
+		}
+
+		if isLet(expr[idx:]) {
+			// the body of the let extends to the end of expr, or to the end of the
+			// argument when parsing the arguments of a function.
+			let, rest, err := tb.letFromExpr(expr[idx:], ctx)
+			if err != nil {
+				err = shiftParseError(err, idx)
+				if !tb.recoverErrors {
+					return nil, err
+				}
+				errs = appendParseErrors(errs, err)
+				if let == nil {
+					let = NewUndefinedWithReasonf("%s", err.Error())
+				}
+			}
+			tree = append(tree, let)
+			tree = append(tree, rest...)
+			break
+		}
+
+		if name, l := readArgumentName(expr[idx:], ctx); l != 0 {
+			// the value of the named argument extends to the end of the argument.
+			var (
+				arg  entry
+				rest Tree
+				err  error
+			)
+			if len(tree) > 0 && isOperatorEntry(tree[len(tree)-1]) {
+				pos := idx + skipBlanks(expr[idx:])
+				err = newParseError(InvalidSyntax, pos, expr[pos:idx+l], "syntax error: named argument '%s' must start an argument", name)
+			} else {
+				arg, rest, err = tb.namedArgumentFromExpr(expr[idx+l:], name, ctx)
+				err = shiftParseError(err, idx+l)
+			}
+			if err != nil {
+				if !tb.recoverErrors {
+					return nil, err
+				}
+				errs = appendParseErrors(errs, err)
+				if arg == nil {
+					arg = NewUndefinedWithReasonf("%s", err.Error())
+				}
+			}
+			tree = append(tree, arg)
+			tree = append(tree, rest...)
+			break
+		}
+
+		if name, l := readLocal(expr[idx:], ctx); l != 0 {
+			tree = append(tree, NewVariable(name))
+			idx += l
+			continue
+		}
+
 		part, ptype, length, err := extractPart(expr[idx:])
 		if err != nil {
+			err = shiftParseError(err, idx)
+			if !tb.recoverErrors {
+				return nil, err
+			}
+			// on error, length is the number of bytes to skip to resume parsing.
+			errs = appendParseErrors(errs, err)
+			tree = append(tree, NewUndefinedWithReasonf("%s", err.Error()))
+			idx += max(length, 1)
+			continue
+		}
+
+		if ptype == blankType {
+			// only returned when the entire expression is empty or only contains blanks.
+			break
+		}
+
+		start := idx + skipBlanks(expr[idx:]) // position of part in expr
+
+		if ptype == listType && start == idx && len(tree) > 0 && !isOperatorEntry(tree[len(tree)-1]) {
+			// a '[' that is glued to the operand before it accesses an element of the operand.
+			ptype = indexType
+		}
+
+		if ctx.argList && ptype == operatorType && start > idx && length == start-idx+1 && (part == "+" || part == "-") {
+			// a single sign preceded by a blank and immediately followed by its operand
+			glued[len(tree)] = true
+		}
+
+		e, err := tb.entryFromPart(part, ptype, start, ctx)
+		if err != nil {
+			if !tb.recoverErrors {
+				return nil, err
+			}
+			errs = appendParseErrors(errs, err)
+			if e == nil {
+				e = NewUndefinedWithReasonf("%s", err.Error())
+			}
+		}
+		tree = append(tree, e)
+
+		idx += length
+	}
+
+	tree = groupUnaryOperators(tree, glued)
+	tree = compileRegexpLiterals(tree)
+
+	// adjust trees that start with "Plus" or "Minus" followed by a "Numberer"
+	if tree.TrunkLen() >= 2 {
+		switch tree[0] {
+		case Plus:
+			tree = tree[1:]
+		case Minus:
+			tree = append(Tree{NewNumberFromInt(-1), Multiply}, tree[1:]...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return tree, errs
+	}
+
+	return tree, nil
+}
+
+// lambdaFromExpr builds a Lambda which body is read from expr.
+// When parsing the arguments of a function, the body ends with the argument: the entries of
+// the arguments that follow are returned in rest.
+func (tb TreeBuilder) lambdaFromExpr(expr string, params []string, ctx parseContext) (entry, Tree, error) {
+	if skipBlanks(expr) == len(expr) {
+		return nil, nil, newParseError(InvalidSyntax, 0, expr, "syntax error: missing body for lambda with parameters (%s)", strings.Join(params, " "))
+	}
+
+	v, err := tb.fromExpr(expr, ctx.withLocals(params...))
+	if err != nil && v == nil {
+		return nil, nil, err
+	}
+
+	if !ctx.argList {
+		return NewLambda(params, v), nil, err
+	}
+
+	args := v.Split()
+
+	var rest Tree
+	for _, a := range args[1:] {
+		rest = append(rest, a...)
+	}
+
+	return NewLambda(params, args[0]), rest, err
+}
+
+// namedArgumentFromExpr builds a NamedArgument which value is read from expr.
+// When parsing the arguments of a function separated with blanks, the value ends with the
+// argument: the entries of the arguments that follow are returned in rest.
+func (tb TreeBuilder) namedArgumentFromExpr(expr, name string, ctx parseContext) (entry, Tree, error) {
+	if skipBlanks(expr) == len(expr) {
+		return nil, nil, newParseError(InvalidSyntax, 0, expr, "syntax error: missing value for named argument '%s'", name)
+	}
+
+	v, err := tb.fromExpr(expr, ctx)
+	if err != nil && v == nil {
+		return nil, nil, err
+	}
+
+	args := v.Split()
+	if len(args) > 1 && !ctx.argList {
+		// a comma-separated argument
+		pos := skipBlanks(expr)
+		return nil, nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing operator or ',' in value of named argument '%s': '%s'", name, strings.TrimSpace(expr))
+	}
+
+	var rest Tree
+	for _, a := range args[1:] {
+		rest = append(rest, a...)
+	}
+	v = args[0]
+
+	if _, ok := namedArgument(v); ok {
+		pos := skipBlanks(expr)
+		return nil, rest, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing value for named argument '%s'", name)
+	}
+
+	return NewNamedArgument(name, v), rest, err
+}
+
+// compileRegexpLiterals replaces the string literals on the right hand side of the '=~' and '!~'
+// operators with a Regexp, so that they are compiled once.
+// An invalid regular expression is replaced with an Undefined that describes the problem.
+func compileRegexpLiterals(tree Tree) Tree {
+	for i := 1; i < len(tree); i++ {
+		if tree[i-1] != Match && tree[i-1] != NotMatch {
+			continue
+		}
+
+		s, ok := tree[i].(String)
+		if !ok || (i+1 < len(tree) && isAccessor(tree[i+1])) {
+			continue
+		}
+
+		re, err := NewRegexp(s.value)
+		if err != nil {
+			tree[i] = NewUndefinedWithReasonf("invalid regular expression %s: %s", s.String(), err.Error())
+			continue
+		}
+		tree[i] = re
+	}
+
+	return tree
+}
+
+// blockFromExpr builds a Block from expr, which holds statements separated by ';' at the
+// positions seps.
+// The names assigned by a statement are locals of the statements that follow it.
+func (tb TreeBuilder) blockFromExpr(expr string, seps []int, ctx parseContext) (Tree, error) {
+	var statements []Tree
+
+	var errs ParseErrors
+
+	from := 0
+	for _, to := range append(seps, len(expr)) {
+		stmt, start := expr[from:to], from
+		from = to + 1
+
+		if skipBlanks(stmt) == len(stmt) {
+			// empty statements (such as after a trailing ';') are ignored
+			continue
+		}
+
+		s, err := tb.statementFromExpr(stmt, ctx)
+		if err != nil {
+			err = shiftParseError(err, start)
+			if !tb.recoverErrors {
+				return nil, err
+			}
+			errs = appendParseErrors(errs, err)
+			if s == nil {
+				s = Tree{NewUndefinedWithReasonf("%s", err.Error())}
+			}
+		}
+
+		if len(s) == 0 {
+			continue
+		}
+
+		if a, ok := s[0].(Assignment); ok && !isUserVariableName(a.Name) {
+			ctx = ctx.withLocals(a.Name)
+		}
+
+		statements = append(statements, s)
+	}
+
+	if len(statements) == 0 {
+		return nil, newParseError(InvalidSyntax, 0, expr, "syntax error: empty script '%s'", expr)
+	}
+
+	if len(errs) > 0 {
+		return Tree{NewBlock(statements...)}, errs
+	}
+
+	return Tree{NewBlock(statements...)}, nil
+}
+
+// statementFromExpr builds the Tree of a statement of a Block: either an Assignment
+// (e.g. `a = :x: * 2`) or an expression.
+func (tb TreeBuilder) statementFromExpr(expr string, ctx parseContext) (Tree, error) {
+	name, l, ok := readAssignment(expr)
+	if !ok {
+		return tb.fromExpr(expr, ctx.nested(false))
+	}
+
+	if skipBlanks(expr[l:]) == len(expr[l:]) {
+		return nil, newParseError(InvalidSyntax, 0, expr, "syntax error: missing value for assignment to '%s'", name)
+	}
+
+	v, err := tb.fromExpr(expr[l:], ctx.nested(false))
+	if err != nil {
+		err = shiftParseError(err, l)
+		if v == nil {
 			return nil, err
 		}
+	}
+
+	return Tree{NewAssignment(name, v)}, err
+}
+
+// argumentsFromExpr parses expr, which holds the arguments of a function call or the elements
+// of a list, separated according to the ArgumentSeparator of the TreeBuilder.
+// start is the position of expr in the expression being parsed.
+// The arguments may be named when ctx allows it (see parseContext.namedArguments).
+func (tb TreeBuilder) argumentsFromExpr(expr string, start int, ctx parseContext) ([]Tree, error) {
+	seps := argumentSeparators(expr)
+
+	if tb.argSeparator == SpaceSeparator || (tb.argSeparator == CommaOrSpaceSeparator && len(seps) == 0) {
+		v, err := tb.fromExpr(expr, ctx.nested(true).withNamedArguments(ctx.namedArguments))
+		if err != nil {
+			err = shiftParseError(err, start)
+			if v == nil {
+				return nil, err
+			}
+		}
+		return v.Split(), err
+	}
+
+	if skipBlanks(expr) == len(expr) {
+		return []Tree{}, nil
+	}
+
+	args := []Tree{}
+
+	var errs ParseErrors
+
+	from := 0
+	for _, to := range append(seps, len(expr)) {
+		argExpr, argStart := expr[from:to], from
+		from = to + 1
+
+		var (
+			arg Tree
+			err error
+		)
+		if skipBlanks(argExpr) == len(argExpr) {
+			err = newParseError(InvalidSyntax, 0, argExpr, "syntax error: missing argument in '%s'", expr)
+		} else {
+			arg, err = tb.argumentFromExpr(argExpr, ctx)
+		}
+		if err != nil {
+			err = shiftParseError(err, start+argStart)
+			if !tb.recoverErrors {
+				return nil, err
+			}
+			errs = appendParseErrors(errs, err)
+			if arg == nil {
+				arg = Tree{NewUndefinedWithReasonf("%s", err.Error())}
+			}
+		}
+
+		args = append(args, arg)
+	}
+
+	if len(errs) > 0 {
+		return args, errs
+	}
+
+	return args, nil
+}
+
+// argumentFromExpr parses expr, a comma-separated argument of a function call.
+// The argument must be a single expression.
+func (tb TreeBuilder) argumentFromExpr(expr string, ctx parseContext) (Tree, error) {
+	arg, err := tb.fromExpr(expr, ctx.nestedArgument())
+	if err != nil {
+		return arg, err
+	}
+
+	if len(arg.Split()) > 1 {
+		pos := skipBlanks(expr)
+		return nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing operator or ',' in argument '%s'", strings.TrimSpace(expr))
+	}
+
+	return arg, nil
+}
+
+// argumentSeparators returns the positions of the ',' that separate the arguments of a function
+// call held in expr.
+// The ',' found in strings, in variable names, in comments or in nested brackets, braces or
+// parentheses are ignored.
+func argumentSeparators(expr string) []int {
+	var indices []int
+
+	depth := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return indices
+			}
+			i += l - 1
+		case c == ':':
+			if v, l, err := readVariable(expr[i:]); err == nil && len(v) > 2 {
+				i += l - 1
+			}
+		case c == '/' && commentLen(expr[i:]) > 0:
+			i += commentLen(expr[i:]) - 1
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// statementSeparators returns the positions of the ';' that separate the statements of expr.
+// The ';' found in strings, in variable names or in nested brackets, braces or parentheses
+// are ignored.
+func statementSeparators(expr string) []int {
+	var indices []int
+
+	depth := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return indices
+			}
+			i += l - 1
+		case c == ':':
+			if v, l, err := readVariable(expr[i:]); err == nil && len(v) > 2 {
+				i += l - 1
+			}
+		case c == '/' && commentLen(expr[i:]) > 0:
+			i += commentLen(expr[i:]) - 1
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ';' && depth == 0:
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+func isAssignment(expr string) bool {
+	_, _, ok := readAssignment(expr)
+	return ok
+}
+
+// readAssignment reads the target of an assignment at the start of expr, that is a name or a
+// user variable followed by '=' (e.g. `a = ...` or `:x: = ...`).
+// It returns the name and the length of expr up to and including the '='.
+func readAssignment(expr string) (string, int, bool) {
+	pos := skipBlanks(expr)
+
+	var l int
+	if pos < len(expr) && expr[pos] == ':' {
+		v, vl, err := readVariable(expr[pos:])
+		if err != nil || len(v) <= 2 || strings.Contains(v, "|") {
+			// a variable with a default value cannot be assigned to
+			return "", 0, false
+		}
+		l = vl
+	} else {
+		l = readIdentifier(expr[pos:])
+		if l == 0 || isKeywordAt(expr, pos, "let") || isKeywordAt(expr, pos, "in") {
+			return "", 0, false
+		}
+	}
+
+	name := expr[pos : pos+l]
+
+	eq := pos + l + skipBlanks(expr[pos+l:])
+	if eq >= len(expr) || expr[eq] != '=' || (eq+1 < len(expr) && (expr[eq+1] == '=' || expr[eq+1] == '~')) {
+		// not an assignment: '==' is a comparison and '=~' a regular expression match
+		return "", 0, false
+	}
+
+	return name, eq + 1, true
+}
+
+// letFromExpr builds a Let from expr, which starts with the 'let' keyword i.e.
+// `let name = value in body`.
+// When parsing the arguments of a function, the body ends with the argument: the entries of
+// the arguments that follow are returned in rest.
+func (tb TreeBuilder) letFromExpr(expr string, ctx parseContext) (entry, Tree, error) {
+	pos := skipBlanks(expr) + len("let")
+	pos += skipBlanks(expr[pos:])
+
+	l := readIdentifier(expr[pos:])
+	if l == 0 {
+		return nil, nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing name for let binding")
+	}
+	name := expr[pos : pos+l]
+
+	pos += l
+	pos += skipBlanks(expr[pos:])
+	if pos == len(expr) || expr[pos] != '=' || strings.HasPrefix(expr[pos:], "==") {
+		return nil, nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing '=' after let binding name '%s'", name)
+	}
+	pos++ // skip '='
+
+	in := indexOfLetIn(expr[pos:])
+	if in < 0 {
+		return nil, nil, newParseError(InvalidSyntax, skipBlanks(expr), expr, "syntax error: missing 'in' for let binding '%s'", name)
+	}
+	if skipBlanks(expr[pos:pos+in]) == in {
+		return nil, nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing value for let binding '%s'", name)
+	}
+
+	value, err := tb.fromExpr(expr[pos:pos+in], ctx.nested(false))
+	if err != nil {
+		err = shiftParseError(err, pos)
+		if value == nil {
+			return nil, nil, err
+		}
+	}
+
+	pos += in + len("in")
+	if skipBlanks(expr[pos:]) == len(expr[pos:]) {
+		return nil, nil, newParseError(InvalidSyntax, pos, expr[pos:], "syntax error: missing body for let binding '%s'", name)
+	}
+
+	body, bodyErr := tb.fromExpr(expr[pos:], ctx.withLocals(name))
+	if bodyErr != nil {
+		bodyErr = shiftParseError(bodyErr, pos)
+		if body == nil {
+			return nil, nil, bodyErr
+		}
+		if err != nil {
+			err = appendParseErrors(appendParseErrors(nil, err), bodyErr)
+		} else {
+			err = bodyErr
+		}
+	}
+
+	if !ctx.argList {
+		return NewLet(name, value, body), nil, err
+	}
+
+	args := body.Split()
+
+	var rest Tree
+	for _, a := range args[1:] {
+		rest = append(rest, a...)
+	}
+
+	return NewLet(name, value, args[0]), rest, err
+}
+
+// entryFromPart builds the Tree entry for a part of an expression read by extractPart.
+// start is the position of the part in the expression being parsed.
+// When recovering from errors, the entry may be returned alongside the errors found in
+// its sub-expressions (e.g. function arguments).
+func (tb TreeBuilder) entryFromPart(part string, ptype exprType, start int, ctx parseContext) (entry, error) {
+	switch ptype {
+	case numericalType:
+		v, err := NewNumberFromString(part)
+		if err != nil {
+			return nil, newParseError(InvalidNumber, start, part, "syntax error: invalid number '%s'", part)
+		}
+		return v, nil
+
+	case stringType:
+		if part[0] != '`' && strings.Contains(part, "${") {
+			return tb.interpolatedStringFromPart(part, start, ctx)
+		}
+		s, err := unquoteString(part)
+		if err != nil {
+			return nil, shiftParseError(err, start)
+		}
+		return NewString(s), nil
+
+	case nullType:
+		return NewNull(), nil
+
+	case boolType:
+		v, err := NewBoolFromString(part)
+		if err != nil {
+			return nil, newParseError(InvalidSyntax, start, part, "syntax error: %s", err.Error())
+		}
+		return v, nil
+
+	case operatorType:
+		opEntry, ok := stringToOperator(part)
+		if !ok {
+			return nil, newParseError(UnknownOperator, start, part, "unknown operator: '%s'", part)
+		}
+		return opEntry, nil
+
+	case functionType:
+		fname, l, _ := readNamedExpressionType(part) //nolint:errcheck // ignore err: we already parsed the function name when in extractPart()
+		if fname == "" {
+			// parenthesis grouping, not a real function per-se.
+			// conceptually, parenthesis grouping is a special case of anonymous identity function
+			v, err := tb.fromExpr(part[l+1:len(part)-1], ctx.nested(false)) // exclude leading '(' and trailing ')'
+			if err != nil {
+				err = shiftParseError(err, start+l+1)
+				if v == nil {
+					return nil, err
+				}
+			}
+			return v, err
+		}
+		args, err := tb.argumentsFromExpr(part[l+1:len(part)-1], start+l+1, ctx.withNamedArguments(true)) // parse the function's arguments: exclude leading '(' and trailing ')'
+		if err != nil && args == nil {
+			return nil, err
+		}
+		if strings.EqualFold(fname, "if") {
+			// if(condition then else) is not a function: its branches must not be evaluated ahead of time.
+			if _, ok := lo.Find(args, func(a Tree) bool { _, ok := namedArgument(a); return ok }); ok {
+				return nil, newParseError(InvalidSyntax, start, part, "syntax error: if() does not accept named arguments")
+			}
+			if len(args) != 3 {
+				pe := newParseError(InvalidSyntax, start, part, "syntax error: if() requires 3 arguments (condition, then, else), got %d", len(args))
+				if err != nil {
+					return nil, appendParseErrors(ParseErrors{pe}, err)
+				}
+				return nil, pe
+			}
+			return NewConditional(args[0], args[1], args[2]), err
+		}
+		bodyFn := BuiltInFunction(fname) // will be nil if it isn't a built-in function (i.e. user-defined or object method)
+		// NOTE: if bodyFn == nil, we are likely dealing with user-defined function. These are dealt with at Evaluation time.
+		// NOTE: user-defined object methods are the remit of objectMethodType.
+		return NewFunction(fname, bodyFn, args...), err
+
+	case objectMethodType:
+		// an objectMethodType represents a method access on a user-defined object
+		fname, l, _ := readNamedExpressionType(part)                                                      //nolint:errcheck // ignore err: we already parsed the function name when in extractPart()
+		args, err := tb.argumentsFromExpr(part[l+1:len(part)-1], start+l+1, ctx.withNamedArguments(true)) // parse the function's arguments: exclude leading '(' and trailing ')'
+		if err != nil && args == nil {
+			return nil, err
+		}
+		path, pErr := objectPath(fname, start)
+		if pErr != nil {
+			return nil, pErr
+		}
+		if ctx.isLocal(path[0]) {
+			// the method of the value of a lambda parameter
+			return append(localPath(path[:len(path)-1]), DotFunction{NewFunction(path[len(path)-1], nil, args...)}), err
+		}
+		return NewObjectMethod(path[0], strings.Join(path[1:], "."), args...), err
+
+	case variableType:
+		if i := strings.IndexByte(part, '|'); i >= 0 {
+			return tb.variableWithDefaultFromPart(part, i, start, ctx)
+		}
+		return NewVariable(part), nil
+
+	case listType:
+		// list elements are separated in the same way as function arguments.
+		elems, err := tb.argumentsFromExpr(part[1:len(part)-1], start+1, ctx.withNamedArguments(false)) // exclude leading '[' and trailing ']'
+		if err != nil && elems == nil {
+			return nil, err
+		}
+		return NewListLiteral(elems...), err
+
+	case mapType:
+		return tb.mapLiteralFromPart(part, start, ctx)
+
+	case indexType:
+		// an indexType is an access to an element of the value of the last expression evaluated in the Tree.
+		return tb.indexAccessorFromPart(part, start, ctx)
+
+	case objectPropertyType:
+		// an objectPropertyType represents a property access on a user-defined object
+		path, err := objectPath(part, start)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.isLocal(path[0]) {
+			// the property of the value of a lambda parameter
+			return localPath(path), nil
+		}
+		// NOTE: the PropertyName of an object followed by the optional chaining accessor
+		// (i.e. "object?.property") is empty: the object itself is accessed.
+		return NewObjectProperty(path[0], strings.Join(path[1:], ".")), nil
+
+	case objectAccessorByPropertyType:
+		// an objectAccessorByPropertyType is an access to a property of an object retrieved from the last expression evaluated in the Tree.
+		if name, ok := strings.CutPrefix(part, "?."); ok {
+			return OptionalDotVariable{DotVariable{NewVariable(name)}}, nil
+		}
+		return DotVariable{
+			NewVariable(part[1:]), // skip the "."
+		}, nil
+
+	case objectAccessorByMethodType:
+		// an objectAccessorByMethodType is an access to a method of an object retrieved from the last expression evaluated in the Tree.
+		accessorLen := strings.Index(part, ".") + 1 // skip the "." or the "?."
+		v, err := tb.fromExpr(part[accessorLen:], ctx.nested(false))
+		if err != nil {
+			err = shiftParseError(err, start+accessorLen)
+			if v == nil {
+				return nil, err
+			}
+		}
+		if len(v) != 1 {
+			// NOTE: this should never happen because we have already extracted the object accessor into a single "part".
+			return nil, newParseError(InvalidObjectAccessor, start, part, "syntax error: invalid object accessor function: '%s'", part)
+		}
+		oaF, ok := v[0].(Function)
+		if !ok {
+			if err == nil {
+				err = newParseError(InvalidObjectAccessor, start, part, "syntax error: invalid object accessor function: '%s'", part)
+			}
+			return nil, err
+		}
+		// a method that has the name of a built-in function (e.g. 'Floor' or 'Filter') is not the built-in function.
+		// NOTE: supporting built-in functions here would turn the object into a prototype model e.g. like JavaScript
+		oaF.BodyFn = nil
+		if accessorLen == 2 {
+			return OptionalDotFunction{DotFunction{oaF}}, err
+		}
+		return DotFunction{oaF}, err
+
+	default:
+		return nil, newParseError(InternalError, start, part, "internal error: unknown expression part type '%T'='%v'", ptype, ptype)
+	}
+}
+
+// objectPath splits the reference to a member of a user-defined object, such as
+// `order.Customer.Address.City`, into its segments.
+func objectPath(ref string, start int) ([]string, error) {
+	path := strings.Split(ref, ".")
+	if lo.Contains(path, "") {
+		return nil, newParseError(InvalidObjectAccessor, start, ref, "syntax error: invalid object reference '%s': empty name", ref)
+	}
+	return path, nil
+}
+
+// localPath returns the Tree that accesses the members of the value of a lambda parameter,
+// such as `x.Customer.Address`: path holds the name of the parameter and then the names of
+// the properties.
+func localPath(path []string) Tree {
+	tree := Tree{NewVariable(path[0])}
+	for _, name := range path[1:] {
+		tree = append(tree, DotVariable{NewVariable(name)})
+	}
+	return tree
+}
+
+// groupUnaryOperators moves the unary operators found in the tree, together with their
+// operand, into a sub-Tree.
+// An operator is unary when it is in operand position (i.e. at the start of the tree or
+// after another operator) or when it was glued to its operand in a function argument list.
+// The operand of a unary operator includes its object accessors and the '**' operations
+// that follow it because '**' has a higher precedence than unary operators: '2 * -3 ** 2'
+// is grouped as '2 * (-(3 ** 2))'.
+// NOTE: a leading '+' or '-' is left in place: it is dealt with by the caller and by
+// Tree.CleanUp.
+func groupUnaryOperators(tree Tree, glued map[int]bool) Tree {
+	outTree := make(Tree, 0, len(tree))
+
+	for i := 0; i < len(tree); {
+		if isUnaryOperatorAt(tree, i, len(outTree) == 0, glued) {
+			if group, n := readUnaryOperation(tree[i:]); n > 0 {
+				outTree = append(outTree, group)
+				i += n
+				continue
+			}
+		}
+
+		outTree = append(outTree, tree[i])
+		i++
+	}
+
+	return outTree
+}
+
+func isUnaryOperatorAt(tree Tree, i int, treeStart bool, glued map[int]bool) bool {
+	op, ok := tree[i].(Operator)
+	if !ok || !unaryOperators(op) {
+		return false
+	}
+
+	if glued[i] {
+		return true
+	}
+
+	if treeStart {
+		// a leading '+' or '-' is dealt with by Tree.CleanUp
+		return op != Plus && op != Minus
+	}
+
+	_, prevIsOperator := tree[i-1].(Operator)
+	return prevIsOperator
+}
+
+// readUnaryOperation reads the unary operator at the start of tree and its operand.
+// It returns the operation as a sub-Tree and the number of entries of tree that it consumed,
+// or 0 if the operator is not followed by an operand.
+func readUnaryOperation(tree Tree) (Tree, int) {
+	op := tree[0].(Operator) //nolint:errcheck // the caller guarantees tree[0] is a unary operator
+
+	group := Tree{}
+	if op != Plus {
+		group = append(group, op)
+	}
+
+	n := 1
+
+	for {
+		operand, l := readUnaryOperand(tree[n:])
+		if l == 0 {
+			return nil, 0
+		}
+		group = append(group, operand...)
+		n += l
+
+		if n >= len(tree) || tree[n] != Power {
+			return group, n
+		}
+
+		group = append(group, Power)
+		n++
+	}
+}
+
+// readUnaryOperand reads an operand, and its object accessors, at the start of tree.
+// The operand may itself be a unary operation.
+func readUnaryOperand(tree Tree) (Tree, int) {
+	if len(tree) == 0 {
+		return nil, 0
+	}
+
+	if op, ok := tree[0].(Operator); ok {
+		if !unaryOperators(op) {
+			return nil, 0
+		}
+		group, n := readUnaryOperation(tree)
+		if n == 0 {
+			return nil, 0
+		}
+		return Tree{group}, n
+	}
+
+	n := 1
+	for n < len(tree) {
+		switch tree[n].(type) {
+		case DotVariable, DotFunction, IndexAccessor:
+			n++
+			continue
+		}
+		break
+	}
+
+	return tree[:n], n
+}
+
+// mapLiteralFromPart builds a MapLiteral from part, which holds the map expression
+// including its enclosing braces i.e. `{"k1": v1, "k2": v2, ...}`.
+func (tb TreeBuilder) mapLiteralFromPart(part string, start int, ctx parseContext) (entry, error) {
+	inner := part[1 : len(part)-1] // exclude leading '{' and trailing '}'
+	if skipBlanks(inner) == len(inner) {
+		return NewMapLiteral(), nil
+	}
+
+	var (
+		entries []MapLiteralEntry
+		errs    ParseErrors
+	)
+
+	from := 0
+	for _, to := range append(topLevelIndices(inner, ','), len(inner)) {
+		e, err := tb.mapLiteralEntryFromExpr(inner[from:to], start+1+from, ctx)
+		if err != nil {
+			if !tb.recoverErrors {
+				return nil, err
+			}
+			errs = appendParseErrors(errs, err)
+		}
+		if e.Value != nil {
+			entries = append(entries, e)
+		}
+		from = to + 1
+	}
+
+	if len(errs) > 0 {
+		return NewMapLiteral(entries...), errs
+	}
+
+	return NewMapLiteral(entries...), nil
+}
+
+// mapLiteralEntryFromExpr parses an entry of a map expression i.e. `"key": value`.
+// start is the position of expr in the expression being parsed.
+func (tb TreeBuilder) mapLiteralEntryFromExpr(expr string, start int, ctx parseContext) (MapLiteralEntry, error) {
+	pos := skipBlanks(expr)
+	if pos == len(expr) {
+		return MapLiteralEntry{}, newParseError(InvalidSyntax, start, expr, "syntax error: empty map entry")
+	}
+
+	if !isStringDelimiter(expr[pos]) {
+		return MapLiteralEntry{}, newParseError(InvalidSyntax, start+pos, expr[pos:], "syntax error: map key must be a string, got '%s'", strings.TrimSpace(expr[pos:]))
+	}
+
+	_, l, err := readString(expr[pos:])
+	if err != nil {
+		return MapLiteralEntry{}, shiftParseError(err, start+pos)
+	}
+
+	key, err := unquoteString(expr[pos : pos+l])
+	if err != nil {
+		return MapLiteralEntry{}, shiftParseError(err, start+pos)
+	}
+
+	pos += l
+	pos += skipBlanks(expr[pos:])
+	if pos == len(expr) || expr[pos] != ':' {
+		return MapLiteralEntry{}, newParseError(InvalidSyntax, start+pos, expr[pos:], "syntax error: missing ':' after map key \"%s\"", key)
+	}
+
+	pos++ // skip ':'
+	if skipBlanks(expr[pos:]) == len(expr[pos:]) {
+		return MapLiteralEntry{}, newParseError(InvalidSyntax, start+pos, expr[pos:], "syntax error: missing value for map key \"%s\"", key)
+	}
+
+	v, err := tb.fromExpr(expr[pos:], ctx.nested(false))
+	if err != nil {
+		return MapLiteralEntry{Key: key, Value: v}, shiftParseError(err, start+pos)
+	}
+
+	return MapLiteralEntry{Key: key, Value: v}, nil
+}
+
+// topLevelIndices returns the positions of sep in expr that are neither in a string
+// nor in nested brackets, braces or parentheses.
+func topLevelIndices(expr string, sep byte) []int {
+	var indices []int
+
+	depth := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return indices
+			}
+			i += l - 1
+		case c == '/' && commentLen(expr[i:]) > 0:
+			i += commentLen(expr[i:]) - 1
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == sep && depth == 0:
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// indexAccessorFromPart builds an IndexAccessor from part, which holds the index
+// expression including its enclosing brackets i.e. "[i]" or "[i:j]".
+func (tb TreeBuilder) indexAccessorFromPart(part string, start int, ctx parseContext) (entry, error) {
+	inner := part[1 : len(part)-1] // exclude leading '[' and trailing ']'
+
+	sep := indexRangeSeparator(inner)
+	if sep < 0 {
+		if skipBlanks(inner) == len(inner) {
+			return nil, newParseError(InvalidSyntax, start, part, "syntax error: missing index in '%s'", part)
+		}
+
+		index, err := tb.indexBoundFromExpr(inner, start+1, ctx)
+		if err != nil && index == nil {
+			return nil, err
+		}
+		return NewIndexAccessor(index), err
+	}
+
+	var errs ParseErrors
+
+	low, err := tb.indexBoundFromExpr(inner[:sep], start+1, ctx)
+	if err != nil {
+		if !tb.recoverErrors {
+			return nil, err
+		}
+		errs = appendParseErrors(errs, err)
+	}
+
+	high, err := tb.indexBoundFromExpr(inner[sep+1:], start+1+sep+1, ctx)
+	if err != nil {
+		if !tb.recoverErrors {
+			return nil, err
+		}
+		errs = appendParseErrors(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return NewRangeIndexAccessor(low, high), errs
+	}
+
+	return NewRangeIndexAccessor(low, high), nil
+}
+
+// variableWithDefaultFromPart builds a Variable that has a default value from part, such as
+// `:discount|0:`. sep is the position of the '|' that separates the name from the default value.
+func (tb TreeBuilder) variableWithDefaultFromPart(part string, sep, start int, ctx parseContext) (entry, error) {
+	name := part[:sep] + ":"
+	if len(name) <= 2 {
+		return nil, newParseError(InvalidVariable, start, part, "syntax error: missing name for variable '%s'", part)
+	}
+
+	expr := part[sep+1 : len(part)-1] // exclude the trailing ':'
+	if skipBlanks(expr) == len(expr) {
+		return nil, newParseError(InvalidVariable, start, part, "syntax error: missing default value for variable '%s'", part)
+	}
+
+	v, err := tb.fromExpr(expr, ctx.nested(false))
+	if err != nil {
+		err = shiftParseError(err, start+sep+1)
+		if v == nil {
+			return nil, err
+		}
+	}
+
+	return NewVariableWithDefault(name, v), err
+}
+
+// indexBoundFromExpr parses a bound of an index accessor.
+// start is the position of expr in the expression being parsed.
+// An omitted bound is returned as a nil Tree.
+func (tb TreeBuilder) indexBoundFromExpr(expr string, start int, ctx parseContext) (Tree, error) {
+	if skipBlanks(expr) == len(expr) {
+		return nil, nil
+	}
+
+	v, err := tb.fromExpr(expr, ctx.nested(false))
+	if err != nil {
+		return v, shiftParseError(err, start)
+	}
+
+	return v, nil
+}
+
+// indexRangeSeparator returns the position of the ':' that separates the bounds of a range
+// index accessor, or -1 when expr is not a range.
+// The ':' found in strings, in nested brackets or that delimit a variable are not separators.
+// NOTE: a variable that immediately follows the separator must be preceded by a blank
+// (e.g. '[1: :x:]'), otherwise it is read as part of the variable name.
+func indexRangeSeparator(expr string) int {
+	depth := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '"', '\'', '`':
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return -1
+			}
+			i += l - 1
+		case '/':
+			if l := commentLen(expr[i:]); l > 0 {
+				i += l - 1
+			}
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ':':
+			if depth != 0 {
+				continue
+			}
+			if v, l, err := readVariable(expr[i:]); err == nil && len(v) > 2 {
+				// this is a variable
+				i += l - 1
+				continue
+			}
+			return i
+		}
+	}
 
-		switch ptype {
-		case numericalType:
-			v, err := NewNumberFromString(part)
-			if err != nil {
-				return nil, err
-			}
-			tree = append(tree, v)
+	return -1
+}
 
-		case stringType:
-			v := NewString(part)
-			tree = append(tree, v)
+// readLambdaParams reads the parameters of a lambda and its arrow i.e. "x ->" or "(a b) ->".
+// The last return value is false when expr does not start with a lambda.
+func readLambdaParams(expr string) ([]string, int, bool) {
+	pos := skipBlanks(expr)
+	if pos == len(expr) {
+		return nil, 0, false
+	}
 
-		case boolType:
-			v, err := NewBoolFromString(part)
-			if err != nil {
-				return nil, err
-			}
-			tree = append(tree, v)
+	var params []string
 
-		case operatorType:
-			opEntry, ok := stringToOperator(part)
-			if !ok {
-				return nil, errors.Errorf("unknown operator: '%s'", part)
+	if expr[pos] == '(' {
+		pos++
+		for {
+			pos += skipBlanks(expr[pos:])
+			if pos == len(expr) {
+				return nil, 0, false
 			}
-			tree = append(tree, opEntry)
-
-		case functionType:
-			fname, l, _ := readNamedExpressionType(part)   //nolint:errcheck // ignore err: we already parsed the function name when in extractPart()
-			v, err := tb.FromExpr(part[l+1 : len(part)-1]) // parse the function's arguments: exclude leading '(' and trailing ')'
-			if err != nil {
-				return nil, err
+			if expr[pos] == ')' {
+				pos++
+				break
 			}
-			if fname == "" {
-				// parenthesis grouping, not a real function per-se.
-				// conceptually, parenthesis grouping is a special case of anonymous identity function
-				tree = append(tree, v)
-			} else {
-				bodyFn := BuiltInFunction(fname) // will be nil if it isn't a built-in function (i.e. user-defined or object method)
-				// NOTE: if bodyFn == nil, we are likely dealing with user-defined function. These are dealt with at Evaluation time.
-				// NOTE: user-defined object methods are the remit of objectMethodType.
-				tree = append(tree, NewFunction(fname, bodyFn, v.Split()...))
+			l := readIdentifier(expr[pos:])
+			if l == 0 {
+				return nil, 0, false
 			}
-
-		case objectMethodType:
-			// an objectMethodType represents a method access on a user-defined object
-			fname, l, _ := readNamedExpressionType(part)   //nolint:errcheck // ignore err: we already parsed the function name when in extractPart()
-			v, err := tb.FromExpr(part[l+1 : len(part)-1]) // parse the function's arguments: exclude leading '(' and trailing ')'
-			if err != nil {
-				return nil, err
+			params = append(params, expr[pos:pos+l])
+			pos += l
+			pos += skipBlanks(expr[pos:])
+			if pos < len(expr) && expr[pos] == ',' {
+				// the parameters may be separated with commas, like comma-separated arguments
+				pos++
 			}
-			splits := strings.SplitN(fname, ".", 2) // there should only ever be exactly 2 parts at this point
-			om := NewObjectMethod(splits[0], splits[1], v.Split()...)
-			tree = append(tree, om)
-
-		case variableType:
-			v := NewVariable(part)
-			tree = append(tree, v)
-
-		case objectPropertyType:
-			// an objectPropertyType represents a property access on a user-defined object
-			splits := strings.SplitN(part, ".", 2) // there should only ever be exactly 2 parts at this point
-			v := NewObjectProperty(splits[0], splits[1])
-			tree = append(tree, v)
-
-		case objectAccessorByPropertyType:
-			// an objectAccessorByPropertyType is an access to a property of an object retrieved from the last expression evaluated in the Tree.
-			tree = append(tree, DotVariable{
-				NewVariable(part[1:]), // skip the "."
-			})
-
-		case objectAccessorByMethodType:
-			// an objectAccessorByMethodType is an access to a method of an object retrieved from the last expression evaluated in the Tree.
-			v, err := tb.FromExpr(part[1:]) // skip the "."
+		}
+	} else {
+		l := readIdentifier(expr[pos:])
+		if l == 0 {
+			return nil, 0, false
+		}
+		params = append(params, expr[pos:pos+l])
+		pos += l
+	}
+
+	pos += skipBlanks(expr[pos:])
+	if !strings.HasPrefix(expr[pos:], "->") {
+		return nil, 0, false
+	}
+
+	return params, pos + 2, true
+}
+
+// isLet returns true when expr starts with the 'let' keyword.
+func isLet(expr string) bool {
+	return isKeywordAt(expr, skipBlanks(expr), "let")
+}
+
+// isKeywordAt returns true when the word at position pos in expr is the keyword kw.
+func isKeywordAt(expr string, pos int, kw string) bool {
+	if !strings.HasPrefix(expr[pos:], kw) {
+		return false
+	}
+	if pos > 0 && isIdentifierChar(expr[pos-1]) {
+		return false
+	}
+	end := pos + len(kw)
+	return end == len(expr) || !isIdentifierChar(expr[end])
+}
+
+// indexOfLetIn returns the position of the 'in' keyword that ends the value of a let binding,
+// or -1 when it is missing.
+// The keywords found in strings or in nested brackets, braces or parentheses are ignored, as
+// well as those of nested let bindings.
+func indexOfLetIn(expr string) int {
+	depth := 0
+	nested := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
 			if err != nil {
-				return nil, err
+				return -1
 			}
-			if len(v) != 1 {
-				// NOTE: this should never happen because we have already extracted the object accessor into a single "part".
-				return nil, errors.Errorf("syntax error: invalid object accessor function: '%s'", part)
+			i += l - 1
+		case c == ':':
+			// skip variables: their name may contain keywords
+			if v, l, err := readVariable(expr[i:]); err == nil && len(v) > 2 {
+				i += l - 1
 			}
-			oaF := v[0].(Function)
-			if oaF.BodyFn != nil {
-				// NOTE: this could be supported but it would turn the object into a prototype model e.g. like JavaScript
-				return nil, errors.Errorf("internal error: invalid object accessor function: '%s' - BodyFn is not empty: this indicates the object's method was confused for a build-in function", part)
+		case c == '/' && commentLen(expr[i:]) > 0:
+			i += commentLen(expr[i:]) - 1
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth != 0:
+			continue
+		case isKeywordAt(expr, i, "let"):
+			nested++
+		case isKeywordAt(expr, i, "in"):
+			if nested == 0 {
+				return i
 			}
-			tree = append(tree, DotFunction{oaF})
+			nested--
+		}
+	}
 
-		case blankType:
-			// only returned when the entire expression is empty or only contains blanks.
-			return tree, nil
+	return -1
+}
 
-		default:
-			return nil, errors.Errorf("internal error: unknown expression part type '%T'='%v'", ptype, ptype)
-		}
+// readArgumentName reads the name of a named argument, such as 'places=' in 'round(:x: places=2)',
+// at the start of expr, when ctx allows named arguments.
+// It returns the name and the length of expr up to and including the '='.
+func readArgumentName(expr string, ctx parseContext) (string, int) {
+	if !ctx.namedArguments {
+		return "", 0
+	}
 
-		idx += length
+	pos := skipBlanks(expr)
+
+	l := readIdentifier(expr[pos:])
+	if l == 0 {
+		return "", 0
 	}
 
-	// adjust trees that start with "Plus" or "Minus" followed by a "Numberer"
-	if tree.TrunkLen() >= 2 {
-		switch tree[0] {
-		case Plus:
-			return tree[1:], nil
-		case Minus:
-			return append(Tree{NewNumberFromInt(-1), Multiply}, tree[1:]...), nil
-		}
+	eq := pos + l + skipBlanks(expr[pos+l:])
+	if eq >= len(expr) || expr[eq] != '=' || (eq+1 < len(expr) && (expr[eq+1] == '=' || expr[eq+1] == '~')) {
+		// not a named argument: '==' is a comparison and '=~' a regular expression match
+		return "", 0
 	}
 
-	return tree, nil
+	return expr[pos : pos+l], eq + 1
+}
+
+// readLocal reads the bare name of a lambda parameter at the start of expr.
+// It returns a length of 0 when expr does not start with the name of a local.
+func readLocal(expr string, ctx parseContext) (string, int) {
+	if len(ctx.locals) == 0 {
+		return "", 0
+	}
+
+	pos := skipBlanks(expr)
+
+	l := readIdentifier(expr[pos:])
+	if l == 0 || !ctx.isLocal(expr[pos:pos+l]) {
+		return "", 0
+	}
+
+	if pos+l < len(expr) && (expr[pos+l] == '(' || expr[pos+l] == '.') {
+		// a function or an object property of the same name.
+		// NOTE: the properties of a local are dealt with by entryFromPart.
+		return "", 0
+	}
+
+	return expr[pos : pos+l], pos + l
+}
+
+// readIdentifier returns the length of the identifier (e.g. a lambda parameter name) at the
+// start of expr, or 0 when there is none.
+func readIdentifier(expr string) int {
+	if len(expr) == 0 || (expr[0] >= '0' && expr[0] <= '9') {
+		return 0
+	}
+
+	i := 0
+	for i < len(expr) && isIdentifierChar(expr[i]) {
+		i++
+	}
+	return i
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isOperatorEntry(e entry) bool {
+	_, ok := e.(Operator)
+	return ok
 }
 
 func stringToOperator(op string) (Operator, bool) {
@@ -145,6 +1427,10 @@ func stringToOperator(op string) (Operator, bool) {
 		return Divide, true
 	case Modulus.String():
 		return Modulus, true
+	case FloorDivide.String():
+		return FloorDivide, true
+	case FloorModulus.String():
+		return FloorModulus, true
 	case Power.String():
 		return Power, true
 	case LShift.String():
@@ -171,6 +1457,28 @@ func stringToOperator(op string) (Operator, bool) {
 		return Or, true
 	case Or2.String():
 		return Or2, true // NOTE: re-route to Or?
+	case Not.String():
+		return Not, true
+	case Not2.String():
+		return Not2, true // NOTE: re-route to Not?
+	case In.String():
+		return In, true
+	case Match.String():
+		return Match, true
+	case NotMatch.String():
+		return NotMatch, true
+	case NotIn.String():
+		return NotIn, true
+	case NullCoalesce.String():
+		return NullCoalesce, true
+	case BitwiseAnd.String():
+		return BitwiseAnd, true
+	case BitwiseOr.String():
+		return BitwiseOr, true
+	case BitwiseXor.String():
+		return BitwiseXor, true
+	case BitwiseNot.String():
+		return BitwiseNot, true
 	default:
 		return "", false
 	}
@@ -178,28 +1486,48 @@ func stringToOperator(op string) (Operator, bool) {
 
 // returns the part extracted as string, the type extracted, the cursor position
 // after extraction or an error.
+// When an error is returned, the cursor position is where parsing may resume, should
+// the caller wish to recover from the error.
 func extractPart(expr string) (string, exprType, int, error) {
 	// left trim blanks
-	pos := 0
-	for _, r := range expr {
-		if !isBlankSpace(r) {
-			break
-		}
-		pos++
-	}
+	pos := skipBlanks(expr)
 
 	// blank: no part
 	if pos == len(expr) {
 		return "", blankType, pos, nil
 	}
 
-	// read part - "string"
-	if expr[pos] == '"' {
-		s, l, err := readString(expr[pos:])
+	// NOTE: skipBlanks has skipped the comments, unless they are not terminated.
+	if strings.HasPrefix(expr[pos:], "/*") {
+		return "", unknownType, len(expr), newParseError(UnterminatedComment, pos, expr[pos:], "syntax error: unterminated comment '%s'", expr[pos:])
+	}
+
+	// read part - "string", 'string' or `raw string`
+	// NOTE: the part holds the delimiters of the string: they tell how to decode it.
+	if isStringDelimiter(expr[pos]) {
+		_, l, err := readString(expr[pos:])
+		if err != nil {
+			return "", unknownType, pos + l, shiftParseError(err, pos)
+		}
+		return expr[pos : pos+l], stringType, pos + l, nil
+	}
+
+	// read part - [list]
+	if expr[pos] == '[' {
+		s, l, err := readList(expr[pos:])
+		if err != nil {
+			return "", unknownType, pos + l, shiftParseError(err, pos)
+		}
+		return s, listType, pos + l, nil
+	}
+
+	// read part - {map}
+	if expr[pos] == '{' {
+		s, l, err := readMap(expr[pos:])
 		if err != nil {
-			return "", unknownType, 0, err
+			return "", unknownType, pos + l, shiftParseError(err, pos)
 		}
-		return s, stringType, pos + l, nil
+		return s, mapType, pos + l, nil
 	}
 
 	// read part - constants
@@ -212,7 +1540,7 @@ func extractPart(expr string) (string, exprType, int, error) {
 	if expr[pos] == ':' {
 		s, l, err := readVariable(expr[pos:])
 		if err != nil {
-			return "", unknownType, 0, err
+			return "", unknownType, pos + l, shiftParseError(err, pos)
 		}
 		return s, variableType, pos + l, nil
 	}
@@ -229,13 +1557,17 @@ func extractPart(expr string) (string, exprType, int, error) {
 				// note: we have already dealt with variableType above
 				return fname, objectPropertyType, pos + lf, nil
 			}
+			if strings.HasPrefix(expr[pos+lf:], "?.") {
+				// a user-defined object which is optional (i.e. "object?.property"): it may be nil.
+				return fname, objectPropertyType, pos + lf, nil
+			}
 			// allow to continue so we can check alphanumerical operator names such as "And", "Or", etc
 		case err != nil:
-			return "", unknownType, 0, err
+			return "", unknownType, pos + lf, err
 		default:
 			fargs, la, err := readFunctionArguments(expr[pos+lf:])
 			if err != nil {
-				return "", unknownType, 0, err
+				return "", unknownType, pos + lf + la, shiftParseError(err, pos+lf)
 			}
 			if strings.Contains(fname, ".") {
 				// user-defined object method found.
@@ -252,27 +1584,35 @@ func extractPart(expr string) (string, exprType, int, error) {
 	// The dot accessor is used after any gal.entry that returns a value that can be treated as an object.
 	// For example "Pi().Add(10).Sub(5)" is a valid expression because "Pi()" returns a gal.Value and
 	// hence a Go object (be it struct or interface).
-	if expr[pos] == '.' {
+	//
+	// The optional chaining accessor "?." is read in the same way: its part starts with "?.".
+	if expr[pos] == '.' || strings.HasPrefix(expr[pos:], "?.") {
+		optional := 0
+		if expr[pos] == '?' {
+			optional = 1
+		}
+
 		// NOTE: we are keeping the leading dot in expr when submitting to readNamedExpressionType().
-		// This means that `fname`` will always be of the form ".name" (i.e. with leading dot).
+		// This means that the name read will always be of the form ".name" (i.e. with leading dot).
 		// Remember that readNamedExpressionType is designed to read past 1 dot at most. In other words,
 		// it will not read ".name.name2" but only ".name".
-		fname, lf, err := readNamedExpressionType(expr[pos:])
+		_, lf, err := readNamedExpressionType(expr[pos+optional:])
+		lf += optional
 		switch {
 		case errors.Is(err, errFunctionNameWithoutParens):
 			// property found on general purpose object
-			return fname, objectAccessorByPropertyType, pos + lf, nil
+			return expr[pos : pos+lf], objectAccessorByPropertyType, pos + lf, nil
 
 		case err != nil:
-			return "", unknownType, 0, err
+			return "", unknownType, pos + lf, err
 
 		default:
 			// method found on general purpose object
 			fargs, la, err := readFunctionArguments(expr[pos+lf:])
 			if err != nil {
-				return "", unknownType, 0, err
+				return "", unknownType, pos + lf + la, shiftParseError(err, pos+lf)
 			}
-			return fname + fargs, objectAccessorByMethodType, pos + lf + la, nil
+			return expr[pos:pos+lf] + fargs, objectAccessorByMethodType, pos + lf + la, nil
 		}
 	}
 
@@ -288,54 +1628,226 @@ func extractPart(expr string) (string, exprType, int, error) {
 	// NOTE: complex numbers are not supported - could be "native" or via function or perhaps even a specialised MultiValue?
 	s, l, err := readNumber(expr[pos:])
 	if err != nil {
-		return "", unknownType, 0, err
+		return "", unknownType, pos + l, shiftParseError(err, pos)
 	}
 	return s, numericalType, pos + l, nil
 }
 
+// readString reads the string literal at the start of expr, which starts with the delimiter
+// of the string: a double-quote, a single-quote or a backtick.
+// It returns the content of the string, without its delimiters nor any decoding.
 func readString(expr string) (string, int, error) {
-	to := 1 // keep leading double-quotes
-	escapes := 0
+	quote := expr[0]
 
-	for i, r := range expr[1:] {
-		to++
-		if expr[i] == '\\' {
-			escapes += 1
+	// NOTE: the string delimiters and escape character are all single-byte, so it is
+	// safe to walk the expression byte by byte.
+	for i := 1; i < len(expr); i++ {
+		if expr[i] == '\\' && quote != '`' {
+			i++ // skip the escaped character
 			continue
 		}
-		if r == '"' && (escapes == 0 || escapes&1 == 0) {
-			break
+		if isInterpolationAt(expr, i) && quote != '`' {
+			// skip the embedded expression: it may hold strings delimited with quote
+			_, l, err := readMap(expr[i+1:])
+			if err != nil {
+				return "", len(expr), newParseError(MissingBrace, i, expr[i:], "syntax error: missing '}' to end embedded expression '%s'", expr[i:])
+			}
+			i += l
+			continue
+		}
+		if expr[i] == quote {
+			return expr[1:i], i + 1, nil
 		}
-		// NOTE: perhaps we should collapse the `\`'s, here?
+	}
+
+	return "", len(expr), newParseError(UnterminatedString, 0, expr, "syntax error: non-terminated string '%s'", expr)
+}
+
+// isInterpolationAt returns true when an embedded expression (i.e. "${...}") starts at
+// position pos of a string literal.
+func isInterpolationAt(s string, pos int) bool {
+	return strings.HasPrefix(s[pos:], "${")
+}
+
+func isStringDelimiter(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
+
+// unquoteString returns the value of the string literal lit, including its delimiters.
+// Raw strings (i.e. `...`) are returned as is while the escape sequences of the other
+// strings are decoded.
+func unquoteString(lit string) (string, error) {
+	quote := lit[0]
+	content := lit[1 : len(lit)-1]
 
-		escapes = 0
+	if quote == '`' {
+		return content, nil
 	}
 
-	if expr[to-1] != '"' {
-		return "", 0, errors.Errorf("syntax error: non-terminated string '%s'", expr[:to])
+	s, err := unescapeString(content, quote)
+	if err != nil {
+		return "", shiftParseError(err, 1) // +1 for the opening delimiter
 	}
 
-	return expr[1 : to-1], to, nil
+	return s, nil
 }
 
-func readVariable(expr string) (string, int, error) {
-	to := 1 // keep leading ':'
+// interpolatedStringFromPart builds an InterpolatedString from part, a string literal
+// including its delimiters, which holds embedded expressions (i.e. "${...}").
+func (tb TreeBuilder) interpolatedStringFromPart(part string, start int, ctx parseContext) (entry, error) {
+	quote := part[0]
+	content := part[1 : len(part)-1]
 
-	for _, r := range expr[1:] {
-		to++
+	var (
+		segments []StringSegment
+		errs     ParseErrors
+	)
+
+	from := 0 // start of the current text segment in content
+
+	addText := func(to int) error {
+		if to == from {
+			return nil
+		}
+		text, err := unescapeString(content[from:to], quote)
+		if err != nil {
+			return shiftParseError(err, start+1+from)
+		}
+		segments = append(segments, StringSegment{Text: text})
+		return nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\\' {
+			i++ // skip the escaped character
+			continue
+		}
+		if !isInterpolationAt(content, i) {
+			continue
+		}
+
+		if err := addText(i); err != nil {
+			return nil, err
+		}
+
+		// NOTE: readString has already checked that the embedded expression is closed.
+		enclosed, l, _ := readMap(content[i+1:]) //nolint:errcheck
+		inner := enclosed[1 : len(enclosed)-1]
+		innerStart := start + 1 + i + 2 // position of inner in the expression
+
+		if skipBlanks(inner) == len(inner) {
+			return nil, newParseError(InvalidSyntax, innerStart, content[i:i+1+l], "syntax error: empty embedded expression in string %s", part)
+		}
+
+		t, err := tb.fromExpr(inner, ctx.nested(false))
+		if err != nil {
+			err = shiftParseError(err, innerStart)
+			if !tb.recoverErrors || t == nil {
+				return nil, err
+			}
+			errs = appendParseErrors(errs, err)
+		}
+		segments = append(segments, StringSegment{Expr: t})
+
+		i += l
+		from = i + 1
+	}
+
+	if err := addText(len(content)); err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 1 && segments[0].Expr == nil {
+		// all the "${" were escaped
+		return NewString(segments[0].Text), nil
+	}
+
+	if len(errs) > 0 {
+		return NewInterpolatedString(segments...), errs
+	}
+
+	return NewInterpolatedString(segments...), nil
+}
+
+// unescapeString decodes the escape sequences of s, the content of a string literal.
+// The escape sequences are those of Go's interpreted string literals: `\n`, `\t`, `\"`,
+// `\\`, `\u00e9`, `\x41`, etc.
+// In a single-quoted string, `\'` is an escape sequence but `\"` is not.
+func unescapeString(s string, quote byte) (string, error) {
+	if !strings.ContainsRune(s, '\\') {
+		return s, nil
+	}
+
+	var sb strings.Builder
+
+	for rest := s; rest != ""; {
+		if strings.HasPrefix(rest, `\$`) {
+			// "\${" is the literal "${" rather than an embedded expression
+			sb.WriteByte('$')
+			rest = rest[2:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(rest, quote)
+		if err != nil {
+			offset := len(s) - len(rest)
+			seq := rest[:min(len(rest), 2)]
+			return "", newParseError(InvalidEscapeSequence, offset, seq, "syntax error: invalid escape sequence '%s' in string %c%s%c", seq, quote, s, quote)
+		}
+
+		if multibyte {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte(byte(r))
+		}
+
+		rest = tail
+	}
+
+	return sb.String(), nil
+}
+
+func readVariable(expr string) (string, int, error) {
+	// NOTE: ':' and the blank characters are all single-byte, so it is safe to walk
+	// the expression byte by byte.
+	for i := 1; i < len(expr); i++ {
+		r := rune(expr[i])
 		if r == ':' {
-			break
+			return expr[:i+1], i + 1, nil
+		}
+		if r == '|' {
+			return readVariableDefault(expr, i+1)
 		}
 		if isBlankSpace(r) {
-			return "", 0, errors.Errorf("syntax error: invalid character '%c' for variable name '%s'", r, expr[:to])
+			// resume after the variable name: it is likely that the closing ':' is missing
+			return "", i, newParseError(InvalidVariable, i, expr[:i+1], "syntax error: invalid character '%c' for variable name '%s'", r, expr[:i+1])
 		}
 	}
 
-	if expr[to-1] != ':' {
-		return "", 0, errors.Errorf("syntax error: missing ':' to end variable '%s'", expr[:to])
+	return "", len(expr), newParseError(InvalidVariable, 0, expr, "syntax error: missing ':' to end variable '%s'", expr)
+}
+
+// readVariableDefault reads the rest of a variable that has a default value, which starts
+// at pos (e.g. `0:` in `:discount|0:`).
+// The default value ends with the closing ':' of the variable: it may only hold blank
+// characters or ':' in its strings.
+func readVariableDefault(expr string, pos int) (string, int, error) {
+	for i := pos; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == ':':
+			return expr[:i+1], i + 1, nil
+		case isStringDelimiter(c):
+			_, l, err := readString(expr[i:])
+			if err != nil {
+				return "", i + l, shiftParseError(err, i)
+			}
+			i += l - 1
+		case isBlankSpace(rune(c)):
+			return "", i, newParseError(InvalidVariable, i, expr[:i+1], "syntax error: invalid character '%c' for variable name '%s'", c, expr[:i+1])
+		}
 	}
 
-	return expr[:to], to, nil
+	return "", len(expr), newParseError(InvalidVariable, 0, expr, "syntax error: missing ':' to end variable '%s'", expr)
 }
 
 // The last "bool" return value of this function is an `ok` type bool.
@@ -354,6 +1866,10 @@ readString:
 			// we read a potential constant name
 			to-- // eject the space character we just read
 			break readString
+		case strings.HasPrefix(expr[to-1:], "?."):
+			// an optional chaining accessor (i.e. "Null?.property")
+			to-- // eject the '?' character we just read
+			break readString
 		default:
 			// not a constant name
 			return "", 0, unknownType, false
@@ -364,6 +1880,8 @@ readString:
 	case "True", "False":
 		// it's a Bool
 		return expr[:to], to, boolType, true
+	case "Null":
+		return expr[:to], to, nullType, true
 	default:
 		return "", 0, unknownType, false
 	}
@@ -384,10 +1902,23 @@ func readNamedExpressionType(expr string) (string, int, error) {
 			return expr[:to], to, nil
 		}
 
+		// an optional chaining accessor (i.e. "object.property?.name") or a '??' operator
+		// is not part of the name
+		if r == '?' {
+			break
+		}
+
+		// an index accessor (i.e. "object.property[...]") is not part of the name
+		if r == '[' {
+			break
+		}
+
 		// second: check for indication of object property (i.e. "object.property.")
+		// The reference to a user-defined object spans all its properties (i.e. "object.property.property")
+		// but an object accessor (i.e. ".property") stops at the next one.
 		if r == '.' {
 			dotCount++
-			if dotCount == 2 {
+			if dotCount == 2 && expr[0] == '.' {
 				break // this is an object property, no parenttheses
 			}
 		}
@@ -402,28 +1933,50 @@ func readNamedExpressionType(expr string) (string, int, error) {
 }
 
 func readFunctionArguments(expr string) (string, int, error) {
+	return readEnclosed(expr, '(', ')', MissingParenthesis, "function arguments")
+}
+
+func readList(expr string) (string, int, error) {
+	return readEnclosed(expr, '[', ']', MissingBracket, "list")
+}
+
+func readMap(expr string) (string, int, error) {
+	return readEnclosed(expr, '{', '}', MissingBrace, "map")
+}
+
+// readEnclosed reads expr, which starts with the opening bracket, up to the matching
+// closing bracket. Brackets found in strings are ignored.
+// The part returned includes the opening and closing brackets.
+func readEnclosed(expr string, opening, closing byte, kind ParseErrorKind, what string) (string, int, error) {
 	to := 1
 	bktCount := 1 // the currently opened bracket
 
 	for i := 1; i < len(expr); i++ {
 		r := expr[i]
 
-		if r == '"' {
+		if isStringDelimiter(r) {
 			_, l, err := readString(expr[to:])
 			if err != nil {
-				return "", 0, err
+				return "", len(expr), shiftParseError(err, to)
 			}
 			to += l
 			i += l - 1
 			continue
 		}
 
+		if l := commentLen(expr[i:]); r == '/' && l > 0 {
+			// comments may hold brackets and quotes
+			to += l
+			i += l - 1
+			continue
+		}
+
 		to++
-		if r == '(' {
+		if r == opening {
 			bktCount++
 			continue
 		}
-		if r == ')' {
+		if r == closing {
 			bktCount--
 			if bktCount == 0 {
 				return expr[:to], to, nil
@@ -431,34 +1984,120 @@ func readFunctionArguments(expr string) (string, int, error) {
 		}
 	}
 
-	return "", 0, errors.Errorf("syntax error: missing ')' for function arguments '%s'", expr[:to])
+	return "", len(expr), newParseError(kind, 0, expr[:to], "syntax error: missing '%c' for %s '%s'", closing, what, expr[:to])
 }
 
 func readNumber(expr string) (string, int, error) {
-	to := 0
+	base, from := numberBase(expr)
+
+	to := from
 	isFloat := false
+	isExp := false
 
-	for i, r := range expr {
-		if isBlankSpace(r) || isOperator(expr[i:]) {
+	for i, r := range expr[from:] {
+		i += from
+
+		if isBlankSpace(r) {
 			break
 		}
+		if isOperator(expr[i:]) {
+			if base != 10 || !isExp || !(r == '+' || r == '-') || !(expr[i-1] == 'e' || expr[i-1] == 'E') {
+				break
+			}
+			// sign of the exponent
+			to = i + 1
+			continue
+		}
 
-		to++
+		to = i + utf8.RuneLen(r)
 
-		if r == '.' && !isFloat {
+		switch {
+		case r == '_' || isDigitOfBase(r, base):
+			continue
+		case base == 10 && r == '.' && !isFloat && !isExp:
 			isFloat = true
 			continue
-		}
-		if r >= '0' && r <= '9' {
+		case base == 10 && (r == 'e' || r == 'E') && i > 0 && !isExp:
+			isExp = true
 			continue
 		}
 
-		return "", 0, errors.Errorf("syntax error: invalid character '%c' for number '%s'", r, expr[:to])
+		kind := InvalidNumber
+		if i == 0 {
+			// not a number at all
+			kind = UnexpectedCharacter
+		}
+		return "", i + skipToken(expr[i:]), newParseError(kind, i, expr[:to], "syntax error: invalid character '%c' for number '%s'", r, expr[:to])
+	}
+
+	if err := checkNumberLiteral(expr[:to], base, from); err != nil {
+		return "", to, err
 	}
 
 	return expr[:to], to, nil
 }
 
+// numberBase returns the base of the number literal at the start of expr and the length of
+// its base prefix (i.e. "0x", "0b" or "0o").
+func numberBase(expr string) (int, int) {
+	if len(expr) < 2 || expr[0] != '0' {
+		return 10, 0
+	}
+
+	switch expr[1] {
+	case 'x', 'X':
+		return 16, 2
+	case 'b', 'B':
+		return 2, 2
+	case 'o', 'O':
+		return 8, 2
+	default:
+		return 10, 0
+	}
+}
+
+func isDigitOfBase(r rune, base int) bool {
+	switch base {
+	case 2:
+		return r == '0' || r == '1'
+	case 8:
+		return r >= '0' && r <= '7'
+	case 16:
+		return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+	default:
+		return r >= '0' && r <= '9'
+	}
+}
+
+// checkNumberLiteral reports the malformed number literals which characters are all valid,
+// such as "0x", "1e" or "1__000".
+// from is the length of the base prefix of the literal.
+func checkNumberLiteral(lit string, base, from int) error {
+	if from == len(lit) && from > 0 {
+		return newParseError(InvalidNumber, 0, lit, "syntax error: missing digits after prefix '%s' for number '%s'", lit[:from], lit)
+	}
+
+	for i := from; i < len(lit); i++ {
+		switch c := lit[i]; {
+		case c == '_':
+			// '_' separates digits, or the base prefix and the first digit (e.g. "0x_FF").
+			before := i == from && from > 0 || i > from && isDigitOfBase(rune(lit[i-1]), base)
+			after := i+1 < len(lit) && isDigitOfBase(rune(lit[i+1]), base)
+			if !before || !after {
+				return newParseError(InvalidNumber, i, lit, "syntax error: '_' must separate digits in number '%s'", lit)
+			}
+
+		case base == 10 && (c == 'e' || c == 'E'):
+			exp := strings.TrimLeft(lit[i+1:], "+-")
+			if exp == "" || !isDigitOfBase(rune(exp[0]), base) {
+				return newParseError(InvalidNumber, i, lit, "syntax error: missing digits in exponent of number '%s'", lit)
+			}
+		}
+	}
+
+	return nil
+}
+
 func squashPlusMinusChain(expr string) (string, int) {
 	to := 0
 	outcomeSign := 1
@@ -484,6 +2123,33 @@ func squashPlusMinusChain(expr string) (string, int) {
 	return sign, to
 }
 
+// skipToken returns the number of bytes up to the next blank character or operator.
+func skipToken(expr string) int {
+	for i, r := range expr {
+		if isBlankSpace(r) || isOperator(expr[i:]) {
+			return i
+		}
+	}
+	return len(expr)
+}
+
+// skipBlanks returns the length of the blanks and comments at the start of expr.
+func skipBlanks(expr string) int {
+	pos := 0
+	for pos < len(expr) {
+		if isBlankSpace(rune(expr[pos])) {
+			pos++
+			continue
+		}
+		if l := commentLen(expr[pos:]); l > 0 {
+			pos += l
+			continue
+		}
+		break
+	}
+	return pos
+}
+
 func isBlankSpace(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\n'
 }
@@ -493,11 +2159,30 @@ func readOperator(s string) (string, int) {
 	case strings.HasPrefix(s, And.String()):
 		return s[:3], 3
 
+	case strings.HasPrefix(s, Not.String()) && (len(s) == 3 || isBlankSpace(rune(s[3]))):
+		// NOTE: unlike And and Or, Not is a prefix: it must be followed by a blank
+		// so that it is not confused with the start of a name.
+		return s[:3], 3
+
+	case isKeywordAt(s, 0, In.String()):
+		// NOTE: 'in' must not be followed by a name character so that it is not confused
+		// with the start of a name (e.g. 'index').
+		return s[:2], 2
+
+	case notInLen(s) > 0:
+		// NOTE: the blanks between 'not' and 'in' are not significant.
+		return NotIn.String(), notInLen(s)
+
 	case strings.HasPrefix(s, Power.String()),
+		strings.HasPrefix(s, FloorDivide.String()),
+		strings.HasPrefix(s, FloorModulus.String()),
 		strings.HasPrefix(s, LShift.String()),
 		strings.HasPrefix(s, RShift.String()),
 		strings.HasPrefix(s, EqualTo.String()),
 		strings.HasPrefix(s, NotEqualTo.String()),
+		strings.HasPrefix(s, Match.String()),
+		strings.HasPrefix(s, NotMatch.String()),
+		strings.HasPrefix(s, NullCoalesce.String()),
 		strings.HasPrefix(s, GreaterThanOrEqual.String()),
 		strings.HasPrefix(s, LessThanOrEqual.String()),
 		strings.HasPrefix(s, And2.String()),
@@ -511,7 +2196,12 @@ func readOperator(s string) (string, int) {
 		strings.HasPrefix(s, Multiply.String()),
 		strings.HasPrefix(s, Modulus.String()),
 		strings.HasPrefix(s, GreaterThan.String()),
-		strings.HasPrefix(s, LessThan.String()):
+		strings.HasPrefix(s, LessThan.String()),
+		strings.HasPrefix(s, Not2.String()),
+		strings.HasPrefix(s, BitwiseAnd.String()),
+		strings.HasPrefix(s, BitwiseOr.String()),
+		strings.HasPrefix(s, BitwiseXor.String()),
+		strings.HasPrefix(s, BitwiseNot.String()):
 		return s[:1], 1
 
 	default:
@@ -519,6 +2209,20 @@ func readOperator(s string) (string, int) {
 	}
 }
 
+// notInLen returns the length of the 'not in' operator at the start of s, or 0 when there is none.
+func notInLen(s string) int {
+	if !isKeywordAt(s, 0, "not") {
+		return 0
+	}
+
+	pos := 3 + skipBlanks(s[3:])
+	if pos == 3 || !isKeywordAt(s, pos, In.String()) {
+		return 0
+	}
+
+	return pos + 2
+}
+
 func isOperator(s string) bool {
 	_, l := readOperator(s)
 	return l != 0
@@ -1,6 +1,7 @@
 package gal_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -287,48 +288,1046 @@ func TestTreeBuilder_FromExpr_Dot_Accessor_Property(t *testing.T) {
 	assert.Equal(t, gal.NewNumberFromFloat(100), gotVal)
 }
 
-// TODO: (!) this is an idea for a future feature
-// func TestTreeBuilder_FromExpr_Arrays(t *testing.T) {
-//	expr := `f(1 2 3)[1]` // simple example
-//	expr := `f(1 2 3)[1+sum(1 2 3)  2+4]` // complex example: formulae and 2 dimensional array
-
-//	funcs := gal.Functions{
-//		"f": func(args ...gal.Value) gal.Value { return gal.NewMultiValue(args...) },
-//	}
-
-//	got := gal.Parse(expr)
-
-//	expectedTree := gal.Tree{
-//		gal.NewFunction(
-//			"f",
-//			nil,
-//			gal.Tree{
-//				gal.NewNumberFromInt(1),
-//			},
-//			gal.Tree{
-//				gal.NewNumberFromInt(2),
-//			},
-//			gal.Tree{
-//				gal.NewNumberFromInt(3),
-//			},
-//			gal.Collection{ // this would work similarly to gal.Dot but with the lhs as the collection and the rhs as the index
-//				gal.Tree{
-//					gal.NewNumberFromInt(1),
-//				},
-//			},
-//		),
-//	}
-
-//	if !cmp.Equal(expectedTree, got) {
-//		t.Error(cmp.Diff(expectedTree, got))
-//      t.FailNow()
-//	}
-
-//	gotVal := got.Eval(gal.WithFunctions(funcs))
-//	expectedVal := gal.NewNumberFromFloat(5.323784)
-
-//	if !cmp.Equal(expectedVal, gotVal) {
-//		t.Error(cmp.Diff(expectedVal, gotVal))
-//      t.FailNow()
-//	}
-// }
+func TestTreeBuilder_FromExpr_IndexAccessor(t *testing.T) {
+	expr := `f(1 2 3)[1+sum(1 2 3)] :list:[-2:] [1 2][0]`
+
+	got := gal.Parse(expr)
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewNumberFromInt(1)},
+			gal.Tree{gal.NewNumberFromInt(2)},
+			gal.Tree{gal.NewNumberFromInt(3)},
+		),
+		gal.NewIndexAccessor(
+			gal.Tree{
+				gal.NewNumberFromInt(1),
+				gal.Plus,
+				gal.NewFunction(
+					"sum",
+					nil,
+					gal.Tree{gal.NewNumberFromInt(1)},
+					gal.Tree{gal.NewNumberFromInt(2)},
+					gal.Tree{gal.NewNumberFromInt(3)},
+				),
+			},
+		),
+		gal.NewVariable(":list:"),
+		gal.NewRangeIndexAccessor(
+			gal.Tree{gal.NewNumberFromInt(-1), gal.Multiply, gal.NewNumberFromInt(2)},
+			nil,
+		),
+		gal.NewListLiteral(
+			gal.Tree{gal.NewNumberFromInt(1)},
+			gal.Tree{gal.NewNumberFromInt(2)},
+		),
+		gal.NewIndexAccessor(gal.Tree{gal.NewNumberFromInt(0)}),
+	}
+
+	if !cmp.Equal(expectedTree, got) {
+		t.Error(cmp.Diff(expectedTree, got))
+		t.FailNow()
+	}
+
+	// an accessor belongs to the argument it follows.
+	got = gal.Parse(`f(:x:[:y:] :z:[:a: : :b:] [:c: :d:][0])`)
+	require.Len(t, got, 1)
+	f, ok := got[0].(gal.Function)
+	require.True(t, ok)
+	assert.Len(t, f.Args, 3)
+
+	_, err := gal.ParseE(`:list:[ ]`)
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.InvalidSyntax, pe.Kind)
+	assert.Equal(t, "syntax error: missing index in '[ ]' (line 1, column 7)", pe.Error())
+}
+
+func TestParseE_ParseError(t *testing.T) {
+	tt := map[string]struct {
+		expr       string
+		wantKind   gal.ParseErrorKind
+		wantOffset int
+		wantLine   int
+		wantColumn int
+		wantToken  string
+	}{
+		"unterminated string": {
+			expr:       `1 + "abc`,
+			wantKind:   gal.UnterminatedString,
+			wantOffset: 4,
+			wantLine:   1,
+			wantColumn: 5,
+			wantToken:  `"abc`,
+		},
+		"variable in function arguments": {
+			expr:       `1 + f(2 :x)`,
+			wantKind:   gal.InvalidVariable,
+			wantOffset: 8,
+			wantLine:   1,
+			wantColumn: 9,
+			wantToken:  `:x`,
+		},
+		"number in nested function arguments": {
+			expr:       `trunc(sin(1 2.3.4) 6)`,
+			wantKind:   gal.InvalidNumber,
+			wantOffset: 15,
+			wantLine:   1,
+			wantColumn: 16,
+			wantToken:  `2.3.`,
+		},
+		"number in dot accessor arguments": {
+			expr:       `Pi().Add(1 2.3.4)`,
+			wantKind:   gal.InvalidNumber,
+			wantOffset: 14,
+			wantLine:   1,
+			wantColumn: 15,
+			wantToken:  `2.3.`,
+		},
+		"missing parenthesis": {
+			expr:       "1 +\n\tf(2 3",
+			wantKind:   gal.MissingParenthesis,
+			wantOffset: 6,
+			wantLine:   2,
+			wantColumn: 3,
+			wantToken:  `(2 3`,
+		},
+		"multi-line variable with a blank": {
+			expr:       "1 +\n(2 *\n  :my var:)",
+			wantKind:   gal.InvalidVariable,
+			wantOffset: 14,
+			wantLine:   3,
+			wantColumn: 6,
+			wantToken:  `:my `,
+		},
+		"unexpected character": {
+			expr:       `1 + é`,
+			wantKind:   gal.UnexpectedCharacter,
+			wantOffset: 4,
+			wantLine:   1,
+			wantColumn: 5,
+			wantToken:  `é`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			tree, err := gal.ParseE(tc.expr)
+			require.Error(t, err)
+			assert.Nil(t, tree)
+
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.wantKind, pe.Kind, pe.Kind.String())
+			assert.Equal(t, tc.wantOffset, pe.Offset)
+			assert.Equal(t, tc.wantLine, pe.Line)
+			assert.Equal(t, tc.wantColumn, pe.Column)
+			assert.Equal(t, tc.wantToken, pe.Token)
+		})
+	}
+}
+
+func TestParse_ParseError(t *testing.T) {
+	got := gal.Parse(`2 * (1 + "abc)`)
+	assert.Equal(t, `undefined: syntax error: non-terminated string '"abc)' (line 1, column 10)`, got.Eval().String())
+}
+
+func TestTreeBuilder_FromExpr_WithErrorRecovery(t *testing.T) {
+	expr := `:price * 2 + f(3 4.5.6) + :qty: + "unterminated`
+
+	tree, err := gal.NewTreeBuilder(gal.WithErrorRecovery()).FromExpr(expr)
+	require.Error(t, err)
+
+	var pes gal.ParseErrors
+	require.ErrorAs(t, err, &pes)
+	require.Len(t, pes, 3)
+
+	assert.Equal(t, gal.InvalidVariable, pes[0].Kind)
+	assert.Equal(t, 6, pes[0].Offset)
+	assert.Equal(t, gal.InvalidNumber, pes[1].Kind)
+	assert.Equal(t, 20, pes[1].Offset)
+	assert.Equal(t, 21, pes[1].Column)
+	assert.Equal(t, gal.UnterminatedString, pes[2].Kind)
+	assert.Equal(t, 34, pes[2].Offset)
+
+	// the first syntax error can be found with errors.As, as with the default mode.
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.InvalidVariable, pe.Kind)
+
+	// best-effort tree: the parts that could not be parsed are replaced with an Undefined.
+	expectedTree := gal.Tree{
+		gal.NewUndefinedWithReasonf("%s", pes[0].Message()),
+		gal.Multiply,
+		gal.NewNumberFromInt(2),
+		gal.Plus,
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewNumberFromInt(3)},
+			gal.Tree{gal.NewUndefinedWithReasonf("%s", pes[1].Message())},
+		),
+		gal.Plus,
+		gal.NewVariable(":qty:"),
+		gal.Plus,
+		gal.NewUndefinedWithReasonf("%s", pes[2].Message()),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	assert.True(t, strings.HasPrefix(tree.Eval().String(), "undefined: syntax error: invalid character ' ' for variable name ':price '"))
+
+	tree, err = gal.NewTreeBuilder(gal.WithErrorRecovery()).FromExpr(`1 + 2`)
+	require.NoError(t, err)
+	assert.Equal(t, "3", tree.Eval().String())
+}
+
+func TestTreeBuilder_FromExpr_UnaryOperators(t *testing.T) {
+	expr := `2 * -3 ** 2 And !f(1 -:x:)`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewNumberFromInt(2),
+		gal.Multiply,
+		gal.Tree{
+			gal.Minus,
+			gal.NewNumberFromInt(3),
+			gal.Power,
+			gal.NewNumberFromInt(2),
+		},
+		gal.And,
+		gal.Tree{
+			gal.Not2,
+			gal.NewFunction(
+				"f",
+				nil,
+				gal.Tree{gal.NewNumberFromInt(1)},
+				gal.Tree{gal.Tree{gal.Minus, gal.NewVariable(":x:")}},
+			),
+		},
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+}
+
+func TestTreeBuilder_FromExpr_ListLiteral(t *testing.T) {
+	expr := `[1 "x" :v: [2 + 3 -4] []]`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewListLiteral(
+			gal.Tree{gal.NewNumberFromInt(1)},
+			gal.Tree{gal.NewString("x")},
+			gal.Tree{gal.NewVariable(":v:")},
+			gal.Tree{
+				gal.NewListLiteral(
+					gal.Tree{gal.NewNumberFromInt(2), gal.Plus, gal.NewNumberFromInt(3)},
+					gal.Tree{gal.Tree{gal.Minus, gal.NewNumberFromInt(4)}},
+				),
+			},
+			gal.Tree{gal.NewListLiteral()},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	_, err = gal.NewTreeBuilder().FromExpr(`[1 [2 3] 4`)
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.MissingBracket, pe.Kind)
+	assert.Equal(t, 0, pe.Offset)
+	assert.Equal(t, "syntax error: missing ']' for list '[1 [2 3] 4' (line 1, column 1)", pe.Error())
+}
+
+func TestTreeBuilder_FromExpr_MapLiteral(t *testing.T) {
+	expr := `{"a": 1 + 2, "b": :x:, "c, d": {"e": [1 2]}, "f": {}}`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewMapLiteral(
+			gal.MapLiteralEntry{Key: "a", Value: gal.Tree{gal.NewNumberFromInt(1), gal.Plus, gal.NewNumberFromInt(2)}},
+			gal.MapLiteralEntry{Key: "b", Value: gal.Tree{gal.NewVariable(":x:")}},
+			gal.MapLiteralEntry{Key: "c, d", Value: gal.Tree{
+				gal.NewMapLiteral(
+					gal.MapLiteralEntry{Key: "e", Value: gal.Tree{
+						gal.NewListLiteral(gal.Tree{gal.NewNumberFromInt(1)}, gal.Tree{gal.NewNumberFromInt(2)}),
+					}},
+				),
+			}},
+			gal.MapLiteralEntry{Key: "f", Value: gal.Tree{gal.NewMapLiteral()}},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantKind  gal.ParseErrorKind
+		wantError string
+	}{
+		"missing brace": {
+			expr:      `{"a": 1`,
+			wantKind:  gal.MissingBrace,
+			wantError: `syntax error: missing '}' for map '{"a": 1' (line 1, column 1)`,
+		},
+		"non-string key": {
+			expr:      `{"a": 1, b: 2}`,
+			wantKind:  gal.InvalidSyntax,
+			wantError: `syntax error: map key must be a string, got 'b: 2' (line 1, column 10)`,
+		},
+		"missing colon": {
+			expr:      `{"a" 1}`,
+			wantKind:  gal.InvalidSyntax,
+			wantError: `syntax error: missing ':' after map key "a" (line 1, column 6)`,
+		},
+		"missing value": {
+			expr:      `{"a": }`,
+			wantKind:  gal.InvalidSyntax,
+			wantError: `syntax error: missing value for map key "a" (line 1, column 6)`,
+		},
+		"empty entry": {
+			expr:      `{"a": 1, }`,
+			wantKind:  gal.InvalidSyntax,
+			wantError: `syntax error: empty map entry (line 1, column 9)`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.wantKind, pe.Kind)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+}
+
+func TestTreeBuilder_FromExpr_Lambda(t *testing.T) {
+	expr := `reduce(filter(:orders: o -> o.Total > 100) (acc o) -> acc + o.Total -1)`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"reduce",
+			gal.Reduce,
+			gal.Tree{
+				gal.NewFunction(
+					"filter",
+					gal.Filter,
+					gal.Tree{gal.NewVariable(":orders:")},
+					gal.Tree{
+						gal.NewLambda(
+							[]string{"o"},
+							gal.Tree{
+								gal.Tree{gal.NewVariable("o"), gal.DotVariable{gal.NewVariable("Total")}},
+								gal.GreaterThan,
+								gal.NewNumberFromInt(100),
+							},
+						),
+					},
+				),
+			},
+			gal.Tree{
+				gal.NewLambda(
+					[]string{"acc", "o"},
+					gal.Tree{
+						gal.NewVariable("acc"),
+						gal.Plus,
+						gal.Tree{gal.NewVariable("o"), gal.DotVariable{gal.NewVariable("Total")}},
+					},
+				),
+			},
+			gal.Tree{gal.Tree{gal.Minus, gal.NewNumberFromInt(1)}},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	// a lambda parameter is only known in the body of the lambda.
+	_, err = gal.ParseE(`map(:items: x -> x * 2) + x`)
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.UnexpectedCharacter, pe.Kind)
+	assert.Equal(t, 26, pe.Offset)
+
+	_, err = gal.ParseE(`map(:items: x -> )`)
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "syntax error: missing body for lambda with parameters (x) (line 1, column 17)", pe.Error())
+}
+
+func TestTreeBuilder_FromExpr_Let(t *testing.T) {
+	expr := `let net = :gross: - :tax: in net * 0.2 + f(let a = net in a 5)`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewLet(
+			"net",
+			gal.Tree{gal.NewVariable(":gross:"), gal.Minus, gal.NewVariable(":tax:")},
+			gal.Tree{
+				gal.NewVariable("net"),
+				gal.Multiply,
+				gal.NewNumber(2, -1),
+				gal.Plus,
+				gal.NewFunction(
+					"f",
+					nil,
+					gal.Tree{gal.NewLet("a", gal.Tree{gal.NewVariable("net")}, gal.Tree{gal.NewVariable("a")})},
+					gal.Tree{gal.NewNumberFromInt(5)},
+				),
+			},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantError string
+	}{
+		"missing name": {
+			expr:      `let = 1 in 2`,
+			wantError: "syntax error: missing name for let binding (line 1, column 5)",
+		},
+		"missing equal sign": {
+			expr:      `let x 1 in x`,
+			wantError: "syntax error: missing '=' after let binding name 'x' (line 1, column 7)",
+		},
+		"missing in": {
+			expr:      `let x = 1 x`,
+			wantError: "syntax error: missing 'in' for let binding 'x' (line 1, column 1)",
+		},
+		"missing value": {
+			expr:      `let x = in x`,
+			wantError: "syntax error: missing value for let binding 'x' (line 1, column 8)",
+		},
+		"missing body": {
+			expr:      `let x = 1 in `,
+			wantError: "syntax error: missing body for let binding 'x' (line 1, column 13)",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, gal.InvalidSyntax, pe.Kind)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+
+	// the name is not known outside of the body.
+	_, err = gal.ParseE(`(let x = 1 in x) + x`)
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, "syntax error: invalid character 'x' for number 'x' (line 1, column 20)", pe.Error())
+}
+
+func TestTreeBuilder_FromExpr_NumberLiterals(t *testing.T) {
+	expr := `1e-9 + 6.02E23 - 0xFF * 0b1010 / 0o755 + 1_000_000 + 0x1e-2`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewNumber(1, -9),
+		gal.Plus,
+		gal.NewNumber(602, 21),
+		gal.Minus,
+		gal.NewNumberFromInt(255),
+		gal.Multiply,
+		gal.NewNumberFromInt(10),
+		gal.Divide,
+		gal.NewNumberFromInt(493),
+		gal.Plus,
+		gal.NewNumberFromInt(1_000_000),
+		gal.Plus,
+		gal.NewNumberFromInt(30),
+		gal.Minus,
+		gal.NewNumberFromInt(2),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantError string
+	}{
+		"invalid binary digit": {
+			expr:      `1 + 0b102`,
+			wantError: "syntax error: invalid character '2' for number '0b102' (line 1, column 9)",
+		},
+		"invalid hexadecimal digit": {
+			expr:      `0xFG`,
+			wantError: "syntax error: invalid character 'G' for number '0xFG' (line 1, column 4)",
+		},
+		"missing digits after prefix": {
+			expr:      `2 * 0o`,
+			wantError: "syntax error: missing digits after prefix '0o' for number '0o' (line 1, column 5)",
+		},
+		"missing exponent digits": {
+			expr:      `1e+ 2`,
+			wantError: "syntax error: missing digits in exponent of number '1e+' (line 1, column 2)",
+		},
+		"double separator": {
+			expr:      `1__000`,
+			wantError: "syntax error: '_' must separate digits in number '1__000' (line 1, column 2)",
+		},
+		"trailing separator": {
+			expr:      `1_000_ + 1`,
+			wantError: "syntax error: '_' must separate digits in number '1_000_' (line 1, column 6)",
+		},
+		"exponent in a float": {
+			expr:      `1.5e3.2`,
+			wantError: "syntax error: invalid character '.' for number '1.5e3.' (line 1, column 6)",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, gal.InvalidNumber, pe.Kind)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+}
+
+func TestTreeBuilder_FromExpr_BitwiseOperators(t *testing.T) {
+	expr := `~:a: & 0xF0 | :b: ^ ~1 && True || False`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.Tree{gal.BitwiseNot, gal.NewVariable(":a:")},
+		gal.BitwiseAnd,
+		gal.NewNumberFromInt(0xF0),
+		gal.BitwiseOr,
+		gal.NewVariable(":b:"),
+		gal.BitwiseXor,
+		gal.Tree{gal.BitwiseNot, gal.NewNumberFromInt(1)},
+		gal.And2,
+		gal.True,
+		gal.Or2,
+		gal.False,
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+	}
+}
+
+func TestTreeBuilder_FromExpr_StringEscapes(t *testing.T) {
+	expr := `"a\"b" + "tab\there\nnew line" + "caf\u00e9 \x41\\" + {"k\"ey": 1}`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewString(`a"b`),
+		gal.Plus,
+		gal.NewString("tab\there\nnew line"),
+		gal.Plus,
+		gal.NewString(`café A\`),
+		gal.Plus,
+		gal.NewMapLiteral(gal.MapLiteralEntry{Key: `k"ey`, Value: gal.Tree{gal.NewNumberFromInt(1)}}),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	// a String prints back to a literal of the same value.
+	for _, s := range []string{`a"b`, "tab\there\nnew line", `back\slash`, "café", "\x00"} {
+		got := gal.Parse(gal.NewString(s).String()).Eval()
+		assert.Equal(t, gal.NewString(s), got)
+	}
+
+	_, err = gal.ParseE(`1 + "a\qb"`)
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.InvalidEscapeSequence, pe.Kind)
+	assert.Equal(t, `syntax error: invalid escape sequence '\q' in string "a\qb" (line 1, column 7)`, pe.Error())
+
+	_, err = gal.ParseE(`{"a\u12": 1}`)
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.InvalidEscapeSequence, pe.Kind)
+	assert.Equal(t, `syntax error: invalid escape sequence '\u' in string "a\u12" (line 1, column 4)`, pe.Error())
+}
+
+func TestTreeBuilder_FromExpr_StringDelimiters(t *testing.T) {
+	expr := "f('say \"hi\" :)' `C:\\temp\\(x)\\n`) + 'it\\'s' + {'k': `v'\"`}"
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewString(`say "hi" :)`)},
+			gal.Tree{gal.NewString(`C:\temp\(x)\n`)},
+		),
+		gal.Plus,
+		gal.NewString("it's"),
+		gal.Plus,
+		gal.NewMapLiteral(gal.MapLiteralEntry{Key: "k", Value: gal.Tree{gal.NewString(`v'"`)}}),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantKind  gal.ParseErrorKind
+		wantError string
+	}{
+		"unterminated single-quoted string": {
+			expr:      `1 + 'abc`,
+			wantKind:  gal.UnterminatedString,
+			wantError: "syntax error: non-terminated string ''abc' (line 1, column 5)",
+		},
+		"unterminated raw string": {
+			expr:      "f(`abc)",
+			wantKind:  gal.UnterminatedString,
+			wantError: "syntax error: non-terminated string '`abc)' (line 1, column 3)",
+		},
+		"escaped double-quote in single-quoted string": {
+			expr:      `'a\"b'`,
+			wantKind:  gal.InvalidEscapeSequence,
+			wantError: `syntax error: invalid escape sequence '\"' in string 'a\"b' (line 1, column 3)`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.wantKind, pe.Kind)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+
+	assert.Empty(t, gal.Comments("`http://example.com` + '/* not a comment */'"))
+}
+
+func TestTreeBuilder_FromExpr_InterpolatedString(t *testing.T) {
+	expr := `"Order ${:id:} exceeds ${trunc(:limit: 2)}\n" + '${"}"}' + "\${not embedded}" + ` + "`${raw}`"
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewInterpolatedString(
+			gal.StringSegment{Text: "Order "},
+			gal.StringSegment{Expr: gal.Tree{gal.NewVariable(":id:")}},
+			gal.StringSegment{Text: " exceeds "},
+			gal.StringSegment{Expr: gal.Tree{
+				gal.NewFunction("trunc", gal.Trunc, gal.Tree{gal.NewVariable(":limit:")}, gal.Tree{gal.NewNumberFromInt(2)}),
+			}},
+			gal.StringSegment{Text: "\n"},
+		),
+		gal.Plus,
+		gal.NewInterpolatedString(gal.StringSegment{Expr: gal.Tree{gal.NewString("}")}}),
+		gal.Plus,
+		gal.NewString("${not embedded}"),
+		gal.Plus,
+		gal.NewString("${raw}"),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantKind  gal.ParseErrorKind
+		wantError string
+	}{
+		"empty embedded expression": {
+			expr:      `"a ${ } b"`,
+			wantKind:  gal.InvalidSyntax,
+			wantError: `syntax error: empty embedded expression in string "a ${ } b" (line 1, column 6)`,
+		},
+		"invalid embedded expression": {
+			expr:      `"a ${2x}"`,
+			wantKind:  gal.InvalidNumber,
+			wantError: "syntax error: invalid character 'x' for number '2x' (line 1, column 7)",
+		},
+		"missing closing brace": {
+			expr:      `"a ${:x: b"`,
+			wantKind:  gal.MissingBrace,
+			wantError: `syntax error: missing '}' to end embedded expression '${:x: b"' (line 1, column 4)`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.wantKind, pe.Kind)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+}
+
+func TestTreeBuilder_FromExpr_MembershipOperators(t *testing.T) {
+	expr := `:country: in :allowed: And "x" not  in "abc" Or index(1) in [1 2]`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewVariable(":country:"),
+		gal.In,
+		gal.NewVariable(":allowed:"),
+		gal.And,
+		gal.NewString("x"),
+		gal.NotIn,
+		gal.NewString("abc"),
+		gal.Or,
+		gal.NewFunction("index", nil, gal.Tree{gal.NewNumberFromInt(1)}),
+		gal.In,
+		gal.NewListLiteral(gal.Tree{gal.NewNumberFromInt(1)}, gal.Tree{gal.NewNumberFromInt(2)}),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+	}
+}
+
+func TestTreeBuilder_FromExpr_RegexpOperators(t *testing.T) {
+	expr := `:code: =~ "^[A-Z]{3}$" && :name: !~ :pattern: || :x: =~ "(" ; s = "abc"; s =~ "b"`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	re, err := gal.NewRegexp("^[A-Z]{3}$")
+	require.NoError(t, err)
+	b, err := gal.NewRegexp("b")
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewBlock(
+			gal.Tree{
+				gal.NewVariable(":code:"),
+				gal.Match,
+				re,
+				gal.And2,
+				gal.NewVariable(":name:"),
+				gal.NotMatch,
+				gal.NewVariable(":pattern:"),
+				gal.Or2,
+				gal.NewVariable(":x:"),
+				gal.Match,
+				gal.NewUndefinedWithReasonf("invalid regular expression \"(\": error parsing regexp: missing closing ): `(`"),
+			},
+			gal.Tree{gal.NewAssignment("s", gal.Tree{gal.NewString("abc")})},
+			gal.Tree{gal.NewVariable("s"), gal.Match, b},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})) {
+		t.Error(cmp.Diff(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})))
+	}
+
+	// the zero Regexp is only equal to itself
+	assert.True(t, gal.Regexp{}.Equal(gal.Regexp{}))
+	assert.False(t, gal.Regexp{}.Equal(b))
+	assert.False(t, b.Equal(gal.Regexp{}))
+}
+
+func TestTreeBuilder_FromExpr_NullCoalescing(t *testing.T) {
+	expr := `:discount: ?? :default|0: * 2 > 1 And :name|"n/a": != Null`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewVariable(":discount:"),
+		gal.NullCoalesce,
+		gal.NewVariableWithDefault(":default:", gal.Tree{gal.NewNumberFromInt(0)}),
+		gal.Multiply,
+		gal.NewNumberFromInt(2),
+		gal.GreaterThan,
+		gal.NewNumberFromInt(1),
+		gal.And,
+		gal.NewVariableWithDefault(":name:", gal.Tree{gal.NewString("n/a")}),
+		gal.NotEqualTo,
+		gal.NewNull(),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+	}
+
+	for expr, wantErr := range map[string]string{
+		`:x|:`:   "syntax error: missing default value for variable ':x|:' (line 1, column 1)",
+		`:|1:`:   "syntax error: missing name for variable ':|1:' (line 1, column 1)",
+		`:x|1 :`: "syntax error: invalid character ' ' for variable name ':x|1 ' (line 1, column 5)",
+	} {
+		_, err := gal.NewTreeBuilder().FromExpr(expr)
+		assert.EqualError(t, err, wantErr, expr)
+	}
+}
+
+func TestTreeBuilder_FromExpr_OptionalChaining(t *testing.T) {
+	expr := `:x:?.Name(1)?.Len??0`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewVariable(":x:"),
+		gal.OptionalDotFunction{gal.DotFunction{gal.NewFunction("Name", nil, gal.Tree{gal.NewNumberFromInt(1)})}},
+		gal.OptionalDotVariable{gal.DotVariable{gal.NewVariable("Len")}},
+		gal.NullCoalesce,
+		gal.NewNumberFromInt(0),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+	}
+}
+
+func TestTreeBuilder_FromExpr_ObjectPaths(t *testing.T) {
+	expr := `map(:orders: o -> o.Customer.Address.City) + o.Customer..City`
+	tree, err := gal.NewTreeBuilder(gal.WithErrorRecovery()).FromExpr(expr)
+	require.Error(t, err)
+	assert.EqualError(t, err, "syntax error: invalid object reference 'o.Customer..City': empty name (line 1, column 46)")
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"map",
+			gal.MapValues,
+			gal.Tree{gal.NewVariable(":orders:")},
+			gal.Tree{gal.NewLambda(
+				[]string{"o"},
+				gal.Tree{
+					gal.Tree{
+						gal.NewVariable("o"),
+						gal.DotVariable{gal.NewVariable("Customer")},
+						gal.DotVariable{gal.NewVariable("Address")},
+						gal.DotVariable{gal.NewVariable("City")},
+					},
+				},
+			)},
+		),
+		gal.Plus,
+		gal.NewUndefinedWithReasonf("syntax error: invalid object reference 'o.Customer..City': empty name"),
+	}
+
+	if !cmp.Equal(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})) {
+		t.Error(cmp.Diff(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})))
+	}
+}
+
+func TestTreeBuilder_FromExpr_CommaArguments(t *testing.T) {
+	expr := `f(1 -2, -:b:, [1, 2]) + g(1, , 2 3)`
+	tree, err := gal.NewTreeBuilder(gal.WithArgumentSeparator(gal.CommaSeparator), gal.WithErrorRecovery()).FromExpr(expr)
+	require.Error(t, err)
+	assert.EqualError(t, err, "syntax error: missing argument in '1, , 2 3' (line 1, column 29)\nsyntax error: missing operator or ',' in argument '2 3' (line 1, column 32)")
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewNumberFromInt(1), gal.Minus, gal.NewNumberFromInt(2)},
+			gal.Tree{gal.NewNumberFromInt(-1), gal.Multiply, gal.NewVariable(":b:")},
+			gal.Tree{gal.NewListLiteral(
+				gal.Tree{gal.NewNumberFromInt(1)},
+				gal.Tree{gal.NewNumberFromInt(2)},
+			)},
+		),
+		gal.Plus,
+		gal.NewFunction(
+			"g",
+			nil,
+			gal.Tree{gal.NewNumberFromInt(1)},
+			gal.Tree{gal.NewUndefinedWithReasonf("syntax error: missing argument in '1, , 2 3'")},
+			gal.Tree{gal.NewUndefinedWithReasonf("syntax error: missing operator or ',' in argument '2 3'")},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})) {
+		t.Error(cmp.Diff(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})))
+	}
+
+	// both separators: a comma ends an argument, otherwise blanks separate the arguments
+	tree, err = gal.NewTreeBuilder(gal.WithArgumentSeparator(gal.CommaOrSpaceSeparator)).FromExpr(`f(1 -2, 3)`)
+	require.NoError(t, err)
+
+	expectedTree = gal.Tree{
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewNumberFromInt(1), gal.Minus, gal.NewNumberFromInt(2)},
+			gal.Tree{gal.NewNumberFromInt(3)},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+	}
+}
+
+func TestTreeBuilder_FromExpr_NamedArguments(t *testing.T) {
+	expr := `round(:x: places=1 + 1 mode="up") + f(a=[1 2] b=) * if(True then=1 2)`
+	tree, err := gal.NewTreeBuilder(gal.WithErrorRecovery()).FromExpr(expr)
+	require.Error(t, err)
+	assert.EqualError(t, err, "syntax error: missing value for named argument 'b' (line 1, column 49)\nsyntax error: if() does not accept named arguments (line 1, column 53)")
+
+	expectedTree := gal.Tree{
+		gal.NewFunction(
+			"round",
+			gal.Round,
+			gal.Tree{gal.NewVariable(":x:")},
+			gal.Tree{gal.NewNamedArgument("places", gal.Tree{gal.NewNumberFromInt(1), gal.Plus, gal.NewNumberFromInt(1)})},
+			gal.Tree{gal.NewNamedArgument("mode", gal.Tree{gal.NewString("up")})},
+		),
+		gal.Plus,
+		gal.NewFunction(
+			"f",
+			nil,
+			gal.Tree{gal.NewNamedArgument("a", gal.Tree{gal.NewListLiteral(gal.Tree{gal.NewNumberFromInt(1)}, gal.Tree{gal.NewNumberFromInt(2)})})},
+			gal.Tree{gal.NewUndefinedWithReasonf("syntax error: missing value for named argument 'b'")},
+		),
+		gal.Multiply,
+		gal.NewUndefinedWithReasonf("syntax error: if() does not accept named arguments"),
+	}
+
+	if !cmp.Equal(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})) {
+		t.Error(cmp.Diff(expectedTree, tree, cmp.AllowUnexported(gal.Undefined{})))
+	}
+
+	// the named arguments are part of the identity of a function call
+	assert.True(t, cmp.Equal(gal.Parse(`round(:x: places=2)`), gal.Parse(`round(:x: places=2)`)))
+	assert.False(t, cmp.Equal(gal.Parse(`round(:x: places=2)`), gal.Parse(`round(:x: places=3)`)))
+	assert.False(t, cmp.Equal(gal.Parse(`round(:x: places=2)`), gal.Parse(`round(:x: mode=2)`)))
+
+	withParams := gal.NewFunction("f", nil, gal.Tree{gal.NewNumberFromInt(1)})
+	withParams.Params = gal.Parameters{{Name: "a"}, {Name: "b", Default: gal.NewNumberFromInt(2)}}
+	assert.False(t, withParams.Equal(gal.NewFunction("f", nil, gal.Tree{gal.NewNumberFromInt(1)})))
+
+	_, err = gal.ParseE(`round(1 + places=2)`)
+	assert.EqualError(t, err, "syntax error: named argument 'places' must start an argument (line 1, column 11)")
+
+	_, err = gal.NewTreeBuilder(gal.WithArgumentSeparator(gal.CommaSeparator)).FromExpr(`round(1, places=2 mode="up")`)
+	assert.EqualError(t, err, "syntax error: missing operator or ',' in value of named argument 'places': '2 mode=\"up\"' (line 1, column 17)")
+}
+
+func TestTreeBuilder_FromExpr_Comments(t *testing.T) {
+	expr := `// total price
+	:price: /* unit */ * f(2 /* (a "quoted" comment) */ 3) // ignored: ;
+	/ 2 + "not // a /* comment */"`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewVariable(":price:"),
+		gal.Multiply,
+		gal.NewFunction("f", nil, gal.Tree{gal.NewNumberFromInt(2)}, gal.Tree{gal.NewNumberFromInt(3)}),
+		gal.Divide,
+		gal.NewNumberFromInt(2),
+		gal.Plus,
+		gal.NewString("not // a /* comment */"),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	_, err = gal.ParseE("1 + 2 /* oops")
+	var pe *gal.ParseError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, gal.UnterminatedComment, pe.Kind)
+	assert.Equal(t, "syntax error: unterminated comment '/* oops' (line 1, column 7)", pe.Error())
+}
+
+func TestComments(t *testing.T) {
+	expr := "a = 1; // first\n\"/* not a comment */\" + /* second\n  */ :x:"
+
+	want := []gal.Comment{
+		{Text: "// first", Offset: 7, Line: 1, Column: 8},
+		{Text: "/* second\n  */", Offset: 40, Line: 2, Column: 25},
+	}
+	got := gal.Comments(expr)
+	assert.Equal(t, want, got)
+	assert.False(t, got[0].IsBlock())
+	assert.True(t, got[1].IsBlock())
+}
+
+func TestTreeBuilder_FromExpr_Block(t *testing.T) {
+	expr := `a = :x: * 2; :y: = a + 1; a / :y:;`
+	tree, err := gal.NewTreeBuilder().FromExpr(expr)
+	require.NoError(t, err)
+
+	expectedTree := gal.Tree{
+		gal.NewBlock(
+			gal.Tree{gal.NewAssignment("a", gal.Tree{gal.NewVariable(":x:"), gal.Multiply, gal.NewNumberFromInt(2)})},
+			gal.Tree{gal.NewAssignment(":y:", gal.Tree{gal.NewVariable("a"), gal.Plus, gal.NewNumberFromInt(1)})},
+			gal.Tree{gal.NewVariable("a"), gal.Divide, gal.NewVariable(":y:")},
+		),
+	}
+
+	if !cmp.Equal(expectedTree, tree) {
+		t.Error(cmp.Diff(expectedTree, tree))
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		expr      string
+		wantError string
+	}{
+		"empty script": {
+			expr:      ` ; ;`,
+			wantError: "syntax error: empty script ' ; ;' (line 1, column 1)",
+		},
+		"missing value": {
+			expr:      `a = 1; b = ; a`,
+			wantError: "syntax error: missing value for assignment to 'b' (line 1, column 7)",
+		},
+		"unknown name": {
+			expr:      `a = b; b = 1`,
+			wantError: "syntax error: invalid character 'b' for number 'b' (line 1, column 5)",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := gal.ParseE(tc.expr)
+			var pe *gal.ParseError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.wantError, pe.Error())
+		})
+	}
+}
@@ -41,14 +41,46 @@ type treeConfig struct {
 	variables Variables
 	functions Functions
 	objects   Objects
+	scope     *scope
+
+	// functionParameters declares the parameters of the user-defined functions.
+	functionParameters FunctionParameters
+
+	// mutableVariables allows the assignments of a script to update the user-defined variables.
+	mutableVariables bool
+
+	// missingVariablesAsNull makes the unknown variables Null rather than Undefined.
+	missingVariablesAsNull bool
+}
+
+// scope holds the variables that are local to a part of an expression, such as the binding
+// of a `let` or the parameters of a lambda.
+// Scopes are layered over the user-defined variables: a variable is looked up in the innermost
+// scope first. This way, local variables never leak into the user-defined Variables.
+type scope struct {
+	parent *scope
+	vars   Variables
+}
+
+// push returns a new scope that holds vars, layered over s.
+func (s *scope) push(vars Variables) *scope {
+	return &scope{
+		parent: s,
+		vars:   vars,
+	}
+}
+
+func (s *scope) get(name string) (Value, bool) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if val, ok := sc.vars.Get(name); ok {
+			return val, true
+		}
+	}
+	return nil, false
 }
 
 // Variable returns the value of the variable specified by name.
-// TODO: (!) add support for arrays and maps via `[...]`
-// ...   NOTE: it may be more adequate to create a new `[]` operator.
-// ...   This would also permit its use on any Value, including those returned from function calls.
-// ...   We would likely need to create new types (unless MultiValue can work for this).
-// ...   An awkward and visually less elegant option would be builtin functions such as GetIndex() (for arrays) and GetKey (for maps).
+// NOTE: arrays and maps are accessed with the IndexAccessor (i.e. `:var:[...]`).
 // ...................................................................
 // ...................................................................
 // ...   Perhaps this indicates that it's time to drop gal.Value   ...
@@ -56,75 +88,140 @@ type treeConfig struct {
 // ...................................................................
 // ...................................................................
 func (tc treeConfig) Variable(name string) Value {
+	if val, ok := tc.scope.get(name); ok {
+		return val
+	}
+
 	if val, ok := tc.variables.Get(name); ok {
 		return val
 	}
 
-	return NewUndefinedWithReasonf("error: unknown user-defined variable '%s'", name)
+	if tc.missingVariablesAsNull {
+		return NewNull()
+	}
+
+	u := NewUndefinedWithReasonf("error: unknown user-defined variable '%s'", name)
+	u.kind = undefinedUnknownVariable // sets it apart from the other Undefined values (see isNullish)
+
+	return u
 }
 
 func (tc treeConfig) ObjectProperty(objProp ObjectProperty) Value {
-	if obj, ok := tc.objects.Get(objProp.ObjectName); ok {
-		return ObjectGetProperty(obj, objProp.PropertyName)
+	path := []string{objProp.ObjectName}
+	if objProp.PropertyName != "" {
+		path = append(path, strings.Split(objProp.PropertyName, ".")...)
+	}
+
+	obj, u := tc.objectPath(path)
+	if u != nil {
+		return u
 	}
-	return NewUndefinedWithReasonf("error: object property '%s': unknown object", objProp.String())
+
+	if len(path) == 1 {
+		// the user-defined object itself (i.e. "object?.property")
+		if val, ok := obj.(Value); ok {
+			return val
+		}
+		return ObjectValue{Object: obj}
+	}
+
+	//nolint:errcheck // the properties of objects are always a Value
+	return obj.(Value)
 }
 
-// Function returns the function definition of the function of the specified name.
+// Function returns the function definition of the function of the specified name, along with
+// the parameters that it declares, if any (see FunctionParameters and ParameterDeclarer).
 // This method is used to look up object methods and user-defined functions.
 // Built-in functions are not looked up here, they are pre-populated at
 // parsing time by the TreeBuilder.
-func (tc treeConfig) Function(name string) FunctionalValue {
-	splits := strings.Split(name, ".") // NOTE: strings.Split(name, ".") allocates a slice every call. strings.Count + strings.Index/LastIndex could avoid allocation in the common “no dot” path.
-	if len(splits) == 2 {
-		// look up the method in the user-provided objects
-		tc.objectMethod(splits[0], splits[1])
-		if obj, ok := tc.objects.Get(splits[0]); ok {
-			// we ignore "ok" here because ObjectGetMethod will populate it with an Undefined.
-			fv, _ := ObjectGetMethod(obj, splits[1])
-			return fv
-		}
-		return func(...Value) Value {
-			return NewUndefinedWithReasonf("error: object reference '%s' is not valid: unknown object or unknown method", name)
-		}
+func (tc treeConfig) Function(name string) (FunctionalValue, Parameters) {
+	if path := strings.Split(name, "."); len(path) >= 2 {
+		// look up the method in the user-provided objects, at any depth (e.g. `order.Customer.Loyalty`)
+		return tc.objectMethod(path[:len(path)-1], path[len(path)-1])
 	}
 
-	if len(splits) >= 2 {
-		// for expressions like `obj.a.b`, the tree should use a Variable or a Function to access `a` and
-		//  then a DotVariable / DotFunction to access `b`.
-		return func(...Value) Value {
-			return NewUndefinedWithReasonf("syntax error: object reference '%s' is not valid: too many dot accessors: max 1 permitted", name)
+	// look up a lambda bound to a local variable (e.g. `let f = x -> x * 2 in f(3)`)
+	if val, ok := tc.scope.get(name); ok {
+		if l, ok := val.(Lambda); ok {
+			return l.Call, l.parameters()
 		}
 	}
 
 	// look up the function in the user-defined functions
 	if val, ok := tc.functions.Get(name); ok {
-		return val
+		params, _ := tc.functionParameters.Get(name)
+		return val, params
 	}
 
 	return func(...Value) Value {
 		return NewUndefinedWithReasonf("error: unknown user-defined function '%s'", name)
-	}
+	}, nil
 }
 
 // TODO: should this return a Function rather?
-func (tc treeConfig) ObjectMethod(objMethod ObjectMethod) FunctionalValue {
-	return tc.objectMethod(objMethod.ObjectName, objMethod.MethodName)
+func (tc treeConfig) ObjectMethod(objMethod ObjectMethod) (FunctionalValue, Parameters) {
+	path := append([]string{objMethod.ObjectName}, strings.Split(objMethod.MethodName, ".")...)
+	return tc.objectMethod(path[:len(path)-1], path[len(path)-1])
 }
 
-func (tc treeConfig) objectMethod(objectName, methodName string) FunctionalValue {
-	if obj, ok := tc.objects.Get(objectName); ok {
-		if fv, ok := ObjectGetMethod(obj, methodName); ok {
-			return fv
-		}
+// objectMethod returns the method of the object designated by path (see objectPath), along
+// with its parameters when the object declares them (see ParameterDeclarer).
+func (tc treeConfig) objectMethod(path []string, methodName string) (FunctionalValue, Parameters) {
+	obj, u := tc.objectPath(path)
+	if u != nil {
 		return func(...Value) Value {
-			return NewUndefinedWithReasonf("error: object '%s' method '%s': unknown or non-callable member (check if it has a pointer receiver)", objectName, methodName)
-		}
+			return u
+		}, nil
+	}
+
+	if len(path) > 1 && isNilObject(obj) {
+		return func(...Value) Value {
+			return NewUndefinedWithReasonf("error: object reference '%s.%s': property '%s' of '%s' is nil (use '%s?.%s' if it is optional)",
+				strings.Join(path, "."), methodName, path[len(path)-1], strings.Join(path[:len(path)-1], "."), strings.Join(path, "."), methodName)
+		}, nil
+	}
+
+	if fv, ok := ObjectGetMethod(unwrapObject(obj), methodName); ok {
+		return fv, objectParameters(unwrapObject(obj), methodName)
 	}
 
 	return func(...Value) Value {
-		return NewUndefinedWithReasonf("error: object '%s' method '%s': unknown object", objectName, methodName)
+		return NewUndefinedWithReasonf("error: object '%s' method '%s': unknown or non-callable member (check if it has a pointer receiver)", strings.Join(path, "."), methodName)
+	}, nil
+}
+
+// objectPath walks the properties of the user-defined object designated by path and returns
+// the last one. For instance, the path of `order.Customer.Address` is ["order", "Customer", "Address"].
+// When a property cannot be accessed, the Undefined value names the segment of path that failed.
+// This is the single resolution path of the references to user-defined objects, whatever their depth.
+func (tc treeConfig) objectPath(path []string) (Object, Value) {
+	obj, ok := tc.objects.Get(path[0])
+	if !ok {
+		return nil, NewUndefinedWithReasonf("error: object reference '%s': unknown object '%s'", strings.Join(path, "."), path[0])
 	}
+
+	for i := 1; i < len(path); i++ {
+		val := ObjectGetProperty(unwrapObject(obj), path[i])
+		if u, ok := val.(Undefined); ok {
+			return nil, NewUndefinedWithReasonf("error: object reference '%s': property '%s' of '%s': %s", strings.Join(path, "."), path[i], strings.Join(path[:i], "."), u.reason)
+		}
+		if isNull(val) && i < len(path)-1 {
+			return nil, NewUndefinedWithReasonf("error: object reference '%s': property '%s' of '%s' is nil (use '%s?.%s' if it is optional)",
+				strings.Join(path, "."), path[i], strings.Join(path[:i], "."), strings.Join(path[:i+1], "."), strings.Join(path[i+1:], "."))
+		}
+
+		obj = val
+	}
+
+	return obj, nil
+}
+
+// unwrapObject returns the Go object held by obj when it is an ObjectValue.
+func unwrapObject(obj Object) Object {
+	if objVal, ok := obj.(ObjectValue); ok {
+		return objVal.Object
+	}
+	return obj
 }
 
 type treeOption func(*treeConfig)
@@ -145,6 +242,21 @@ func WithFunctions(funcs Functions) treeOption {
 	}
 }
 
+// WithFunctionParameters is a functional parameter for Tree evaluation.
+// It declares the parameters of the user-defined functions provided WithFunctions, so that
+// they can be called with named arguments and default values:
+//
+//	gal.WithFunctionParameters(gal.FunctionParameters{
+//		"fee": {{Name: "amount"}, {Name: "rate", Default: gal.NewNumberFromFloat(0.2)}},
+//	})
+//
+// allows `fee(100)`, `fee(100 rate=0.1)` and `fee(rate=0.1 amount=100)`.
+func WithFunctionParameters(params FunctionParameters) treeOption {
+	return func(cfg *treeConfig) {
+		cfg.functionParameters = params
+	}
+}
+
 // WithObjects is a functional parameter for Tree evaluation.
 // It provides user-defined Objects.
 // These objects can carry both properties and methods that can be accessed
@@ -154,3 +266,41 @@ func WithObjects(objects Objects) treeOption {
 		cfg.objects = objects
 	}
 }
+
+// WithMutableVariables is a functional parameter for Tree evaluation.
+// It allows the assignments to user variables in a script (e.g. `:total: = :price: * 2; ...`)
+// to update the Variables provided WithVariables.
+// Without it, such assignments are local to the script and the Variables are never modified.
+func WithMutableVariables() treeOption {
+	return func(cfg *treeConfig) {
+		cfg.mutableVariables = true
+	}
+}
+
+// WithMissingVariablesAsNull is a functional parameter for Tree evaluation.
+// It makes the variables that are not provided WithVariables evaluate to Null rather than
+// to an Undefined error. This suits data where optional fields may be absent.
+func WithMissingVariablesAsNull() treeOption {
+	return func(cfg *treeConfig) {
+		cfg.missingVariablesAsNull = true
+	}
+}
+
+// withScope is a functional parameter for Tree evaluation.
+// It provides the local variables of the enclosing expression.
+func withScope(sc *scope) treeOption {
+	return func(cfg *treeConfig) {
+		cfg.scope = sc
+	}
+}
+
+// options returns the functional parameters that reproduce tc.
+// The nested expressions of a Tree are evaluated with them so that they share the whole
+// configuration of the enclosing expression: variables, scope, functions, modes, etc.
+func (tc treeConfig) options() []treeOption {
+	return []treeOption{
+		func(cfg *treeConfig) {
+			*cfg = tc
+		},
+	}
+}
@@ -19,8 +19,13 @@ type valueCalculation interface {
 	Divide(Value) Value
 	PowerOf(Value) Value
 	Mod(Value) Value
+	FloorDivide(Value) Value
+	FloorMod(Value) Value
 	LShift(Value) Value
 	RShift(Value) Value
+	BitwiseAnd(Value) Value
+	BitwiseOr(Value) Value
+	BitwiseXor(Value) Value
 }
 
 type valueComparison interface {
@@ -63,6 +68,12 @@ type Booler interface {
 	Bool() Bool
 }
 
+// Container is implemented by the Value's that hold other values, such as MultiValue.
+// It supports the `in` and `not in` operators.
+type Container interface {
+	Contains(Value) Bool
+}
+
 type Evaler interface {
 	Eval() Value
 }
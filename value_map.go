@@ -0,0 +1,148 @@
+package gal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map is an associative container of Value's, indexed by String keys.
+// It remembers the order in which its keys were first set.
+//
+// A Map can be instantiated within an expression with the map syntax:
+// `{"a": 1, "b": :x:}`.
+// Its values are accessed by key with the Dot accessor (e.g. `:m:.a`) or the
+// IndexAccessor (e.g. `:m:["a"]`).
+type Map struct {
+	Undefined
+	keys   []string
+	values map[string]Value
+}
+
+// MapEntry is a key / value pair of a Map.
+type MapEntry struct {
+	Key   string
+	Value Value
+}
+
+// NewMap returns a Map that holds the entries in the order they are supplied.
+// When a key is repeated, the last value wins but the key keeps its first position.
+func NewMap(entries ...MapEntry) Map {
+	m := Map{values: make(map[string]Value, len(entries))}
+
+	for _, e := range entries {
+		if _, ok := m.values[e.Key]; !ok {
+			m.keys = append(m.keys, e.Key)
+		}
+		m.values[e.Key] = e.Value
+	}
+
+	return m
+}
+
+// NewMapFromGo returns a Map that holds the values of m, converted to gal.Value's.
+// The keys are sorted since Go maps are not ordered.
+func NewMapFromGo(m map[string]any) (Map, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]MapEntry, 0, len(m))
+
+	for _, k := range keys {
+		v, err := goAnyToGalType(m[k])
+		if err != nil {
+			return Map{}, err
+		}
+		entries = append(entries, MapEntry{Key: k, Value: v})
+	}
+
+	return NewMap(entries...), nil
+}
+
+// Equal satisfies the external Equaler interface such as in `testify` assertions and the `cmp` package
+// Note that the current implementation defines equality as both maps holding the same keys with
+// values that are equal, regardless of the order of the keys.
+func (m Map) Equal(other Map) bool {
+	if m.Size() != other.Size() {
+		return false
+	}
+
+	for k, v := range m.values {
+		ov, ok := other.values[k]
+		if !ok || v.EqualTo(ov) == False {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m Map) EqualTo(other Value) Bool {
+	if v, ok := other.(Map); ok {
+		return NewBool(m.Equal(v))
+	}
+
+	return False
+}
+
+func (m Map) NotEqualTo(other Value) Bool {
+	return m.EqualTo(other).Not()
+}
+
+// Get returns the value held by the map for key.
+func (m Map) Get(key string) Value {
+	v, ok := m.values[key]
+	if !ok {
+		return NewUndefinedWithReasonf("key '%s' does not exist in map", key)
+	}
+
+	return v
+}
+
+// Contains returns True when key is a String that is a key of the map.
+func (m Map) Contains(key Value) Bool {
+	k, ok := key.(String)
+	if !ok {
+		return False
+	}
+
+	_, ok = m.values[k.value]
+
+	return NewBool(ok)
+}
+
+// Keys returns the keys of the map, in order.
+func (m Map) Keys() []string {
+	return append([]string{}, m.keys...)
+}
+
+func (m Map) Size() int {
+	return len(m.keys)
+}
+
+// ToGoMap returns the content of the map as Go types.
+// Numbers become int64 when they are integers and float64 otherwise, Maps become
+// map[string]any and MultiValue's become []any.
+func (m Map) ToGoMap() map[string]any {
+	goMap := make(map[string]any, len(m.keys))
+	for _, k := range m.keys {
+		goMap[k] = galTypeToGoAny(m.values[k])
+	}
+
+	return goMap
+}
+
+func (m Map) String() string {
+	entries := make([]string, 0, len(m.keys))
+	for _, k := range m.keys {
+		entries = append(entries, fmt.Sprintf("%q: %s", k, m.values[k].String()))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func (m Map) AsString() String {
+	return NewString(m.String())
+}
@@ -3,12 +3,10 @@ package gal
 import "strings"
 
 // MultiValue is a container of zero or more Value's.
-// For the time being, it is only usable and useful with functions.
 // Functions can accept a MultiValue, and also return a MultiValue.
 // This allows a function to effectively return multiple values as a MultiValue.
-// TODO: we could add a syntax to instantiate a MultiValue within an expression.
-// ...   perhaps along the lines of [[v1 v2 ...]] or simply a built-in function such as
-// ...   MultiValue(...) - nothing stops the user from creating their own for now :-)
+// A MultiValue can also be instantiated within an expression with the list syntax:
+// `[v1 v2 ...]`.
 //
 // TODO: implement other methods such as Add, LessThan, etc (if meaningful)
 type MultiValue struct {
@@ -37,6 +35,29 @@ func (m MultiValue) Equal(other MultiValue) bool {
 	return true
 }
 
+func (m MultiValue) EqualTo(other Value) Bool {
+	if v, ok := other.(MultiValue); ok {
+		return NewBool(m.Equal(v))
+	}
+
+	return False
+}
+
+func (m MultiValue) NotEqualTo(other Value) Bool {
+	return m.EqualTo(other).Not()
+}
+
+// Contains returns True when one of the values of the MultiValue is equal to val.
+func (m MultiValue) Contains(val Value) Bool {
+	for _, v := range m.values {
+		if val.EqualTo(v) == True {
+			return True
+		}
+	}
+
+	return False
+}
+
 func (m MultiValue) String() string {
 	var vals []string
 	for _, val := range m.values {
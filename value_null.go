@@ -0,0 +1,79 @@
+package gal
+
+// Null is a special gal.Value that indicates the absence of a value, such as an optional
+// field that is not set.
+//
+// Unlike Undefined, Null is not an error: it can be held in variables, passed to functions,
+// compared with `==` and `!=` and replaced with a default value with the `??` operator.
+// All other operations on Null are undefined.
+type Null struct {
+	Undefined
+}
+
+func NewNull() Null {
+	return Null{}
+}
+
+// Equal satisfies the external Equaler interface such as in testify assertions and the cmp package
+func (Null) Equal(Null) bool {
+	return true
+}
+
+func (Null) EqualTo(other Value) Bool {
+	return NewBool(isNull(other))
+}
+
+func (n Null) NotEqualTo(other Value) Bool {
+	return n.EqualTo(other).Not()
+}
+
+func (Null) String() string {
+	return "Null"
+}
+
+func (n Null) AsString() String {
+	return NewString(n.String())
+}
+
+// IsUndefined returns false: Null is a value, unlike Undefined.
+func (Null) IsUndefined() bool {
+	return false
+}
+
+func isNull(val Value) bool {
+	_, ok := val.(Null)
+	return ok
+}
+
+// isNullish returns true when val is Null or the Undefined value of an unknown variable.
+// These are the values replaced by the right hand side of the `??` operator and by the
+// default value of a variable (e.g. `:discount|0:`).
+func isNullish(val Value) bool {
+	if isNull(val) {
+		return true
+	}
+
+	u, ok := val.(Undefined)
+	return ok && u.kind == undefinedUnknownVariable
+}
+
+// calculateNull calculates the operations that involve Null.
+// Null is only equal to Null: any other operation is undefined, except for testing
+// whether Null is held in a MultiValue with `in` and `not in`.
+// The last return value is false when neither lhs nor rhs is Null.
+func calculateNull(lhs Value, op Operator, rhs Value) (Value, bool) {
+	if !isNull(lhs) && !isNull(rhs) {
+		return nil, false
+	}
+
+	switch {
+	case op == EqualTo:
+		return NewBool(isNull(lhs) && isNull(rhs)), true
+	case op == NotEqualTo:
+		return NewBool(!isNull(lhs) || !isNull(rhs)), true
+	case (op == In || op == NotIn) && !isNull(rhs):
+		return nil, false
+	default:
+		return NewUndefinedWithReasonf("operator '%s' cannot be applied to Null: '%s' %s '%s'", op.String(), lhs.String(), op.String(), rhs.String()), true
+	}
+}
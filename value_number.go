@@ -2,6 +2,7 @@ package gal
 
 import (
 	"math/big"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
@@ -30,7 +31,36 @@ func NewNumberFromFloat(f float64) Number {
 	return Number{value: d}
 }
 
+// NewNumberFromString returns the Number represented by s.
+// Besides the decimal notation (e.g. "12.5", "-3", "1e-9" or "6.02E23"), s may hold an integer
+// with a base prefix: "0x" (hexadecimal), "0b" (binary) or "0o" (octal), such as "0xFF".
+// In all cases, '_' may separate the digits (e.g. "1_000_000" or "0b1010_0101").
 func NewNumberFromString(s string) (Number, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Number{}, errors.Errorf("invalid number '%s': too many signs", s)
+	}
+
+	if base, from := numberBase(digits); base != 10 {
+		// NOTE: with base 0, SetString accepts the base prefixes and checks that '_' only
+		// separates digits.
+		i, ok := new(big.Int).SetString(digits, 0)
+		if !ok || from == len(digits) {
+			return Number{}, errors.Errorf("invalid number '%s'", s)
+		}
+		if strings.HasPrefix(s, "-") {
+			i.Neg(i)
+		}
+		return Number{value: decimal.NewFromBigInt(i, 0)}, nil
+	}
+
+	if strings.Contains(digits, "_") {
+		if err := checkNumberLiteral(digits, 10, 0); err != nil {
+			return Number{}, errors.Errorf("invalid number '%s': '_' must separate digits", s)
+		}
+		s = strings.ReplaceAll(s, "_", "")
+	}
+
 	d, err := decimal.NewFromString(s)
 	if err != nil {
 		return Number{}, errors.WithStack(err)
@@ -103,6 +133,84 @@ func (n Number) Mod(other Value) Value {
 	return NewUndefinedWithReasonf("NaN: %s", other.String())
 }
 
+// FloorDivide returns the integer quotient of n and other, rounded towards negative infinity
+// (e.g. -7 ~/ 2 is -4).
+func (n Number) FloorDivide(other Value) Value {
+	q, _, u := n.divMod(other, false)
+	if u != nil {
+		return u
+	}
+
+	return Number{value: q}
+}
+
+// FloorMod returns the remainder of the FloorDivide of n by other.
+// Its sign is that of other (e.g. -7 %% 2 is 1 and 7 %% -2 is -1).
+func (n Number) FloorMod(other Value) Value {
+	_, r, u := n.divMod(other, false)
+	if u != nil {
+		return u
+	}
+
+	return Number{value: r}
+}
+
+// EuclideanDivide returns the integer quotient of the Euclidean division of n by other,
+// such that the remainder is never negative (e.g. `ediv(-7 -2)` is 4).
+func (n Number) EuclideanDivide(other Value) Value {
+	q, _, u := n.divMod(other, true)
+	if u != nil {
+		return u
+	}
+
+	return Number{value: q}
+}
+
+// EuclideanMod returns the remainder of the EuclideanDivide of n by other.
+// It is never negative (e.g. `emod(-7 2)` and `emod(-7 -2)` are both 1).
+func (n Number) EuclideanMod(other Value) Value {
+	_, r, u := n.divMod(other, true)
+	if u != nil {
+		return u
+	}
+
+	return Number{value: r}
+}
+
+// divMod returns the integer quotient and the remainder of the division of n by other,
+// floored or Euclidean.
+// The results are exact, whatever the size and the precision of the numbers.
+func (n Number) divMod(other Value, euclidean bool) (decimal.Decimal, decimal.Decimal, Value) {
+	v, ok := other.(Numberer)
+	if !ok {
+		return decimal.Decimal{}, decimal.Decimal{}, NewUndefinedWithReasonf("NaN: %s", other.String())
+	}
+
+	d := v.Number().value
+	if d.IsZero() {
+		return decimal.Decimal{}, decimal.Decimal{}, NewUndefinedWithReasonf("division by zero")
+	}
+
+	// QuoRem truncates the quotient: the remainder has the sign of n.
+	q, r := n.value.QuoRem(d, 0)
+
+	one := decimal.NewFromInt(1)
+
+	switch {
+	case r.IsZero():
+		// exact division
+	case euclidean && r.IsNegative() && d.IsPositive():
+		q, r = q.Sub(one), r.Add(d)
+	case euclidean && r.IsNegative():
+		q, r = q.Add(one), r.Sub(d)
+	case !euclidean && r.IsNegative() != d.IsNegative():
+		// the floored remainder has the sign of the divisor
+		q, r = q.Sub(one), r.Add(d)
+	}
+
+	return q, r, nil
+}
+
 func (n Number) IntPart() Value {
 	return Number{
 		value: n.value.Truncate(0),
@@ -143,6 +251,46 @@ func (n Number) RShift(other Value) Value {
 	return NewUndefinedWithReasonf("NaN: %s", other.String())
 }
 
+func (n Number) BitwiseAnd(other Value) Value {
+	return n.bitwise(other, "and", (*big.Int).And)
+}
+
+func (n Number) BitwiseOr(other Value) Value {
+	return n.bitwise(other, "or", (*big.Int).Or)
+}
+
+func (n Number) BitwiseXor(other Value) Value {
+	return n.bitwise(other, "xor", (*big.Int).Xor)
+}
+
+// BitwiseNot returns the bitwise complement of n, that is -n - 1 (as with Go's '^x').
+func (n Number) BitwiseNot() Value {
+	if !n.value.IsInteger() {
+		return NewUndefinedWithReasonf("invalid non-integer bitwise not: %s", n.String())
+	}
+
+	return Number{
+		value: decimal.NewFromBigInt(new(big.Int).Not(n.value.BigInt()), 0),
+	}
+}
+
+// bitwise applies the bitwise operation fn to n and other, as arbitrary-size integers.
+// Negative numbers behave as if they were represented in two's complement.
+func (n Number) bitwise(other Value, name string, fn func(z, x, y *big.Int) *big.Int) Value {
+	v, ok := other.(Numberer)
+	if !ok {
+		return NewUndefinedWithReasonf("NaN: %s", other.String())
+	}
+
+	if !n.value.IsInteger() || !v.Number().value.IsInteger() {
+		return NewUndefinedWithReasonf("invalid non-integer bitwise %s: %s, %s", name, n.String(), v.Number().String())
+	}
+
+	return Number{
+		value: decimal.NewFromBigInt(fn(new(big.Int), n.value.BigInt(), v.Number().value.BigInt()), 0),
+	}
+}
+
 func (n Number) Neg() Number {
 	return Number{
 		value: n.value.Neg(),
@@ -220,6 +368,36 @@ func (n Number) Trunc(precision int32) Number {
 	}
 }
 
+// The rounding modes of Number.Round.
+const (
+	RoundHalfUp   = "half_up"   // rounds half away from zero: 2.5 is 3 and -2.5 is -3
+	RoundHalfEven = "half_even" // rounds half to the nearest even digit (banker's rounding): 2.5 is 2
+	RoundUp       = "up"        // rounds away from zero
+	RoundDown     = "down"      // rounds towards zero, like Trunc
+	RoundCeiling  = "ceiling"   // rounds towards +infinity
+	RoundFloor    = "floor"     // rounds towards -infinity
+)
+
+// Round rounds the number to the specified number of decimal places, according to mode.
+func (n Number) Round(places int32, mode string) Value {
+	switch mode {
+	case RoundHalfUp:
+		return Number{value: n.value.Round(places)}
+	case RoundHalfEven:
+		return Number{value: n.value.RoundBank(places)}
+	case RoundUp:
+		return Number{value: n.value.RoundUp(places)}
+	case RoundDown:
+		return Number{value: n.value.RoundDown(places)}
+	case RoundCeiling:
+		return Number{value: n.value.RoundCeil(places)}
+	case RoundFloor:
+		return Number{value: n.value.RoundFloor(places)}
+	default:
+		return NewUndefinedWithReasonf("unknown rounding mode '%s'", mode)
+	}
+}
+
 func (n Number) Factorial() Value {
 	if !n.value.IsInteger() || n.value.IsNegative() {
 		return NewUndefinedWithReasonf("Factorial: requires a positive integer, cannot accept %s", n.String())
@@ -45,6 +45,54 @@ func TestNewNumberFromString(t *testing.T) {
 			want:    Number{},
 			wantErr: true,
 		},
+		{
+			name: "it creates a number from scientific notation",
+			args: args{s: "6.02E23"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(602, 21)},
+		},
+		{
+			name: "it creates a number with a negative exponent",
+			args: args{s: "1e-9"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(1, -9)},
+		},
+		{
+			name: "it creates a number from a hexadecimal string",
+			args: args{s: "-0xFF"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(-255, 0)},
+		},
+		{
+			name: "it creates a number from a binary string",
+			args: args{s: "0b1010"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(10, 0)},
+		},
+		{
+			name: "it creates a number from an octal string",
+			args: args{s: "0o755"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(493, 0)},
+		},
+		{
+			name: "it creates a number with digit separators",
+			args: args{s: "1_000_000.000_1"},
+			want: Number{Undefined: Undefined{}, value: decimal.New(10000000001, -4)},
+		},
+		{
+			name:    "it returns an error when a digit separator is misplaced",
+			args:    args{s: "1__000"},
+			want:    Number{},
+			wantErr: true,
+		},
+		{
+			name:    "it returns an error when a digit is invalid for the base",
+			args:    args{s: "0b102"},
+			want:    Number{},
+			wantErr: true,
+		},
+		{
+			name:    "it returns an error when the base prefix has no digits",
+			args:    args{s: "0x"},
+			want:    Number{},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -412,6 +460,33 @@ func TestNumber_Mod(t *testing.T) {
 	}
 }
 
+func TestNumber_FloorAndEuclideanDivision(t *testing.T) {
+	tests := []struct {
+		name                                     string
+		n, other                                 Number
+		floorDiv, floorMod, euclidDiv, euclidMod string
+	}{
+		{name: "positive by positive", n: NewNumberFromInt(7), other: NewNumberFromInt(2), floorDiv: "3", floorMod: "1", euclidDiv: "3", euclidMod: "1"},
+		{name: "negative by positive", n: NewNumberFromInt(-7), other: NewNumberFromInt(2), floorDiv: "-4", floorMod: "1", euclidDiv: "-4", euclidMod: "1"},
+		{name: "positive by negative", n: NewNumberFromInt(7), other: NewNumberFromInt(-2), floorDiv: "-4", floorMod: "-1", euclidDiv: "-3", euclidMod: "1"},
+		{name: "negative by negative", n: NewNumberFromInt(-7), other: NewNumberFromInt(-2), floorDiv: "3", floorMod: "-1", euclidDiv: "4", euclidMod: "1"},
+		{name: "exact division", n: NewNumberFromInt(-8), other: NewNumberFromInt(2), floorDiv: "-4", floorMod: "0", euclidDiv: "-4", euclidMod: "0"},
+		{name: "decimal numbers", n: NewNumber(-55, -1), other: NewNumber(2, 0), floorDiv: "-3", floorMod: "0.5", euclidDiv: "-3", euclidMod: "0.5"},
+		{name: "large numbers", n: NewNumber(1, 30), other: NewNumberFromInt(-3), floorDiv: "-333333333333333333333333333334", floorMod: "-2", euclidDiv: "-333333333333333333333333333333", euclidMod: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.floorDiv, tt.n.FloorDivide(tt.other).String(), "FloorDivide")
+			assert.Equal(t, tt.floorMod, tt.n.FloorMod(tt.other).String(), "FloorMod")
+			assert.Equal(t, tt.euclidDiv, tt.n.EuclideanDivide(tt.other).String(), "EuclideanDivide")
+			assert.Equal(t, tt.euclidMod, tt.n.EuclideanMod(tt.other).String(), "EuclideanMod")
+		})
+	}
+
+	assert.Equal(t, "undefined: division by zero", NewNumberFromInt(1).FloorDivide(NewNumberFromInt(0)).String())
+	assert.Equal(t, "undefined: division by zero", NewNumberFromInt(1).EuclideanMod(NewNumberFromInt(0)).String())
+}
+
 func TestNumber_IntPart(t *testing.T) {
 	type fields struct {
 		Undefined Undefined
@@ -481,7 +556,7 @@ func TestNumber_LShift(t *testing.T) {
 			name:   "left shift a positive and a negative number",
 			fields: fields{value: decimal.New(5, 0)},
 			args:   args{other: Number{value: decimal.New(-3, 0)}},
-			want:   Undefined{"invalid negative left shift"},
+			want:   Undefined{reason: "invalid negative left shift"},
 		},
 	}
 	for _, tt := range tests {
@@ -527,7 +602,7 @@ func TestNumber_RShift(t *testing.T) {
 			name:   "left shift a positive and a negative number",
 			fields: fields{value: decimal.New(5, 0)},
 			args:   args{other: Number{value: decimal.New(-3, 0)}},
-			want:   Undefined{"invalid negative right shift"},
+			want:   Undefined{reason: "invalid negative right shift"},
 		},
 	}
 	for _, tt := range tests {
@@ -543,6 +618,170 @@ func TestNumber_RShift(t *testing.T) {
 	}
 }
 
+func TestNumber_BitwiseAnd(t *testing.T) {
+	type fields struct {
+		Undefined Undefined
+		value     decimal.Decimal
+	}
+	type args struct {
+		other Value
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   Value
+	}{
+		{
+			name:   "bitwise and two positive numbers",
+			fields: fields{value: decimal.New(0b1100, 0)},
+			args:   args{other: Number{value: decimal.New(0b1010, 0)}},
+			want:   Number{value: decimal.New(0b1000, 0)},
+		},
+		{
+			name:   "bitwise and a negative and a positive number",
+			fields: fields{value: decimal.New(-1, 0)},
+			args:   args{other: Number{value: decimal.New(0xFF, 0)}},
+			want:   Number{value: decimal.New(0xFF, 0)},
+		},
+		{
+			name:   "bitwise and a non-integer number",
+			fields: fields{value: decimal.New(15, -1)},
+			args:   args{other: Number{value: decimal.New(1, 0)}},
+			want:   Undefined{reason: "invalid non-integer bitwise and: 1.5, 1"},
+		},
+		{
+			name:   "bitwise and a non-number",
+			fields: fields{value: decimal.New(1, 0)},
+			args:   args{other: NewMultiValue(NewString("a"))},
+			want:   Undefined{reason: `NaN: "a"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Number{
+				Undefined: tt.fields.Undefined,
+				value:     tt.fields.value,
+			}
+			if got := n.BitwiseAnd(tt.args.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Number.BitwiseAnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber_BitwiseOr(t *testing.T) {
+	type fields struct {
+		Undefined Undefined
+		value     decimal.Decimal
+	}
+	type args struct {
+		other Value
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   Value
+	}{
+		{
+			name:   "bitwise or two positive numbers",
+			fields: fields{value: decimal.New(0b1100, 0)},
+			args:   args{other: Number{value: decimal.New(0b1010, 0)}},
+			want:   Number{value: decimal.New(0b1110, 0)},
+		},
+		{
+			name:   "bitwise or beyond 64 bits",
+			fields: fields{value: decimal.New(1, 20)},
+			args:   args{other: Number{value: decimal.New(1, 0)}},
+			want:   Number{value: decimal.RequireFromString("100000000000000000001")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Number{
+				Undefined: tt.fields.Undefined,
+				value:     tt.fields.value,
+			}
+			if got := n.BitwiseOr(tt.args.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Number.BitwiseOr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber_BitwiseXor(t *testing.T) {
+	type fields struct {
+		Undefined Undefined
+		value     decimal.Decimal
+	}
+	type args struct {
+		other Value
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   Value
+	}{
+		{
+			name:   "bitwise xor two positive numbers",
+			fields: fields{value: decimal.New(0b1100, 0)},
+			args:   args{other: Number{value: decimal.New(0b1010, 0)}},
+			want:   Number{value: decimal.New(0b0110, 0)},
+		},
+		{
+			name:   "bitwise xor a negative and a positive number",
+			fields: fields{value: decimal.New(-2, 0)},
+			args:   args{other: Number{value: decimal.New(1, 0)}},
+			want:   Number{value: decimal.New(-1, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Number{
+				Undefined: tt.fields.Undefined,
+				value:     tt.fields.value,
+			}
+			if got := n.BitwiseXor(tt.args.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Number.BitwiseXor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber_BitwiseNot(t *testing.T) {
+	tests := []struct {
+		name  string
+		value decimal.Decimal
+		want  Value
+	}{
+		{
+			name:  "bitwise not a positive number",
+			value: decimal.New(5, 0),
+			want:  Number{value: decimal.New(-6, 0)},
+		},
+		{
+			name:  "bitwise not a negative number",
+			value: decimal.New(-6, 0),
+			want:  Number{value: decimal.New(5, 0)},
+		},
+		{
+			name:  "bitwise not a non-integer number",
+			value: decimal.New(5, -1),
+			want:  Undefined{reason: "invalid non-integer bitwise not: 0.5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Number{value: tt.value}
+			if got := n.BitwiseNot(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Number.BitwiseNot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNumber_Neg(t *testing.T) {
 	type fields struct {
 		Undefined Undefined
@@ -655,7 +894,7 @@ func TestNumber_Sqrt(t *testing.T) {
 		{
 			name:   "sqrt of a negative number",
 			fields: fields{value: decimal.New(-4, 0)},
-			want:   Undefined{"square root of negative number: -4"},
+			want:   Undefined{reason: "square root of negative number: -4"},
 		},
 	}
 	for _, tt := range tests {
@@ -831,7 +1070,7 @@ func TestNumber_Factorial(t *testing.T) {
 		{
 			name:   "factorial of a negative number",
 			fields: fields{value: decimal.New(-5, 0)},
-			want:   Undefined{"Factorial: requires a positive integer, cannot accept -5"},
+			want:   Undefined{reason: "Factorial: requires a positive integer, cannot accept -5"},
 		},
 	}
 	for _, tt := range tests {
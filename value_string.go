@@ -1,6 +1,9 @@
 package gal
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 type String struct {
 	Undefined
@@ -135,8 +138,20 @@ func (s String) RShift(other Value) Value {
 	return String{value: s.value[:int64(len(s.value))-v.Number().value.IntPart()]}
 }
 
+// Contains returns True when the string representation of val is a substring of s.
+func (s String) Contains(val Value) Bool {
+	if v, ok := val.(Stringer); ok {
+		return NewBool(strings.Contains(s.value, v.AsString().value))
+	}
+
+	return False
+}
+
+// String returns the string as a literal that parses back to the same value: its special
+// characters are escaped (e.g. `"a\"b\n"`), including the "${" that would otherwise start
+// an embedded expression.
 func (s String) String() string {
-	return `"` + s.value + `"`
+	return escapeInterpolation(strconv.Quote(s.value))
 }
 
 func (s String) RawString() string {
@@ -10,3 +10,43 @@ func TestMultiValueString(t *testing.T) {
 	v := NewMultiValue(NewNumberFromInt(123), NewString("abc"), NewBool(true))
 	assert.Equal(t, `123,"abc",True`, v.String())
 }
+
+func TestMapString(t *testing.T) {
+	m := NewMap(
+		MapEntry{Key: "b", Value: NewNumberFromInt(1)},
+		MapEntry{Key: "a", Value: NewString("x")},
+		MapEntry{Key: "b", Value: True},
+	)
+	assert.Equal(t, `{"b": True, "a": "x"}`, m.String())
+	assert.Equal(t, []string{"b", "a"}, m.Keys())
+}
+
+func TestStringString(t *testing.T) {
+	v := NewString("a\"b\\c\n\té")
+	assert.Equal(t, `"a\"b\\c\n\té"`, v.String())
+	assert.Equal(t, "a\"b\\c\n\té", v.RawString())
+}
+
+func TestRegexpLRU(t *testing.T) {
+	c := newRegexpLRU(2)
+
+	a, err := c.compile("a+")
+	assert.NoError(t, err)
+	_, err = c.compile("b+")
+	assert.NoError(t, err)
+
+	again, err := c.compile("a+")
+	assert.NoError(t, err)
+	assert.Same(t, a, again)
+
+	// "b+" is the least recently used: it is evicted.
+	_, err = c.compile("c+")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, c.len())
+	assert.Contains(t, c.entries, "a+")
+	assert.NotContains(t, c.entries, "b+")
+
+	_, err = c.compile("(")
+	assert.Error(t, err)
+	assert.Equal(t, 2, c.len())
+}
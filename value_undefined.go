@@ -15,8 +15,19 @@ import "fmt"
 // an Undefined value when RShift() is called on it.
 type Undefined struct {
 	reason string // optional
+	kind   undefinedKind
 }
 
+// undefinedKind sets apart the Undefined values that the evaluation treats specially.
+type undefinedKind int
+
+const (
+	// undefinedError is an evaluation error.
+	undefinedError undefinedKind = iota
+	// undefinedUnknownVariable is the value of a variable that is not defined (see isNullish).
+	undefinedUnknownVariable
+)
+
 func NewUndefined() Undefined {
 	return Undefined{}
 }
@@ -80,6 +91,14 @@ func (u Undefined) Mod(Value) Value {
 	return u
 }
 
+func (u Undefined) FloorDivide(Value) Value {
+	return u
+}
+
+func (u Undefined) FloorMod(Value) Value {
+	return u
+}
+
 func (u Undefined) LShift(Value) Value {
 	return u
 }
@@ -88,6 +107,18 @@ func (u Undefined) RShift(Value) Value {
 	return u
 }
 
+func (u Undefined) BitwiseAnd(Value) Value {
+	return u
+}
+
+func (u Undefined) BitwiseOr(Value) Value {
+	return u
+}
+
+func (u Undefined) BitwiseXor(Value) Value {
+	return u
+}
+
 func (Undefined) And(other Value) Bool {
 	return Bool{Undefined: NewUndefinedWithReasonf("error: '%T':'%s' cannot use And with Undefined", other, other.String())}
 }
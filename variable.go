@@ -1,7 +1,13 @@
 package gal
 
+import "strings"
+
+// Variable is a Tree entry that holds the value of a user-defined or a local variable.
+// When Default is set, it replaces the value of the variable when the variable is unknown
+// or Null (e.g. `:discount|0:`).
 type Variable struct {
-	Name string
+	Name    string
+	Default Tree
 }
 
 func NewVariable(name string) Variable {
@@ -10,10 +16,20 @@ func NewVariable(name string) Variable {
 	}
 }
 
+func NewVariableWithDefault(name string, defaultValue Tree) Variable {
+	return Variable{
+		Name:    name,
+		Default: defaultValue,
+	}
+}
+
 func (v Variable) Calculate(val entry, op Operator, cfg *treeConfig) entry {
 	varName := v.Name
 
 	rhsVal := cfg.Variable(varName)
+	if v.Default != nil && isNullish(rhsVal) {
+		rhsVal = v.Default.Eval(cfg.options()...)
+	}
 	if u, ok := rhsVal.(Undefined); ok {
 		return u
 	}
@@ -29,5 +45,9 @@ func (v Variable) Calculate(val entry, op Operator, cfg *treeConfig) entry {
 }
 
 func (v Variable) String() string {
-	return v.Name
+	if v.Default == nil {
+		return v.Name
+	}
+
+	return strings.TrimSuffix(v.Name, ":") + "|" + strings.TrimRight(v.Default.String(), "\n") + ":"
 }